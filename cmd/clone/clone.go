@@ -6,9 +6,8 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
-	"fmt"
+	"os"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/emersonmello/claro/internal"
 	"github.com/emersonmello/claro/internal/tui"
 	"github.com/spf13/cobra"
@@ -16,18 +15,27 @@ import (
 
 // Clone represents the clone command
 func Clone() *cobra.Command {
+	var assignment string
+	var resume bool
 	cloneCmd := &cobra.Command{
 		Use:   "clone",
 		Short: "Clone all students assignments from a GitHub Classroom",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
 			if !tui.GitHubCliInstalled {
-				tui.UserGitHubPAT = internal.GetAndSaveToken()
+				token, err := internal.GetAndSaveToken()
+				if err != nil {
+					return err
+				}
+				tui.UserGitHubPAT = token
 			}
-			if _, err := tea.NewProgram(internal.NewCloneModel()).Run(); err != nil {
-				fmt.Println("Error running program:", err)
+			if internal.OutputMode != internal.OutputTUI {
+				return internal.RunClone(assignment, resume, internal.NewReporter(os.Stdout))
 			}
-			return nil
+			return internal.RunTUI(internal.NewCloneModel(assignment, resume))
 		},
 	}
+	cloneCmd.Flags().StringVar(&assignment, "assignment", "", "ID of the assignment to clone, instead of selecting the classroom and the assignment from lists (required when the output is not 'tui')")
+	cloneCmd.Flags().BoolVar(&resume, "resume", false, "continue the interrupted clone, leaving out the repositories it already cloned")
 	return cloneCmd
 }
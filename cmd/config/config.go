@@ -10,12 +10,79 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// Config represents the clone command
+// Config represents the config command
 func Config() *cobra.Command {
 	configCmd := &cobra.Command{
 		Use:   "config",
 		Short: "Configure claro's properties (commit message, filename, etc)",
-		RunE:  internal.ConfigCmd,
+		Long: "Configure claro's properties (commit message, filename, etc)\n" +
+			"Without a subcommand, an interactive menu is shown.",
+		Args: cobra.NoArgs,
+		RunE: internal.ConfigCmd,
 	}
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the value of a configuration key",
+		Args:  cobra.ExactArgs(1),
+		RunE:  internal.ConfigGetCmd,
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "set <key> <value>",
+		Short: "Store the value of a configuration key in the config file",
+		Args:  cobra.ExactArgs(2),
+		RunE:  internal.ConfigSetCmd,
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "unset <key>",
+		Short: "Remove a configuration key from the config file, restoring its default value",
+		Args:  cobra.ExactArgs(1),
+		RunE:  internal.ConfigUnsetCmd,
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List all configuration keys, their values and sources (default, file, env)",
+		Args:  cobra.NoArgs,
+		RunE:  internal.ConfigListCmd,
+	})
+	configCmd.AddCommand(&cobra.Command{
+		Use:   "edit",
+		Short: "Open the config file in $EDITOR",
+		Args:  cobra.NoArgs,
+		RunE:  internal.ConfigEditCmd,
+	})
+	migrateCmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade the config file to the current version and, optionally, convert it to another format",
+		Args:  cobra.NoArgs,
+		RunE:  internal.ConfigMigrateCmd,
+	}
+	migrateCmd.Flags().String("format", "", "convert the config file to this format (env, yaml, toml)")
+	configCmd.AddCommand(migrateCmd)
+
+	profileCmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage configuration profiles, e.g., one per course or institution",
+		Long: "Manage configuration profiles, e.g., one per course or institution\n" +
+			"Each profile has its own claro's properties, GitHub API host, OS keyring token entry and default clone directory.",
+	}
+	profileCmd.AddCommand(&cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a profile from the current configuration",
+		Args:  cobra.ExactArgs(1),
+		RunE:  internal.ProfileCreateCmd,
+	})
+	profileCmd.AddCommand(&cobra.Command{
+		Use:   "use <name>",
+		Short: "Mark a profile as the default profile",
+		Args:  cobra.ExactArgs(1),
+		RunE:  internal.ProfileUseCmd,
+	})
+	profileCmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List the profiles, marking the default one with '*'",
+		Args:  cobra.NoArgs,
+		RunE:  internal.ProfileListCmd,
+	})
+	configCmd.AddCommand(profileCmd)
 	return configCmd
 }
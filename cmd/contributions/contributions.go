@@ -0,0 +1,44 @@
+// Package contributions
+package contributions
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/emersonmello/claro/internal"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Contributions represents the contributions command
+func Contributions() *cobra.Command {
+	var appendToGradeFile bool
+	contributionsCmd := &cobra.Command{
+		Use:   "contributions <directory-with-student-submissions>",
+		Short: "Report the commits and lines added and removed by each team member",
+		Long: tui.LongHelpMsg("Report the commits and the lines added and removed by each author of the students' repositories, mapped to the team members' GitHub logins.\n" +
+			"A Markdown report named 'contributions-<repository-name>.md' is written to the directory"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New(tui.UseErrorMsg("contributions"))
+			}
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
+			}
+			// The commits are read with git, whatever the git backend
+			if _, err := exec.LookPath("git"); err != nil {
+				return errors.New(i18n.T("git.required", "contributions"))
+			}
+			cmd.SilenceUsage = true
+			return internal.RunContributions(args[0], appendToGradeFile, internal.NewReporter(os.Stdout))
+		},
+	}
+	contributionsCmd.Flags().BoolVar(&appendToGradeFile, "append", false, "also write the report to the grade files, so it is included in the feedback")
+	return contributionsCmd
+}
@@ -0,0 +1,47 @@
+// Package diff
+package diff
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/emersonmello/claro/internal"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Diff represents the diff command
+func Diff() *cobra.Command {
+	var starter string
+	diffCmd := &cobra.Command{
+		Use:   "diff <directory-with-student-submissions>",
+		Short: "Compare each student's repository with the assignment's starter code",
+		Long: tui.LongHelpMsg("Compare each student's repository with the assignment's starter code.\n" +
+			"A patch file named 'diff-<repository-name>.patch', containing the diffstat and the full diff of the student's changes, is written to the directory"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New(tui.UseErrorMsg("diff"))
+			}
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
+			}
+			// Only git can compare the repositories, whatever the git backend
+			if _, err := exec.LookPath("git"); err != nil {
+				return errors.New(i18n.T("git.required", "diff"))
+			}
+			cmd.SilenceUsage = true
+			if internal.OutputMode != internal.OutputTUI {
+				return internal.RunDiff(args[0], starter, internal.NewReporter(os.Stdout))
+			}
+			return internal.RunTUI(internal.NewDiffModel(args[0], starter))
+		},
+	}
+	diffCmd.Flags().StringVar(&starter, "starter", "", "starter code repository URL (default is the one recorded by the clone command)")
+	return diffCmd
+}
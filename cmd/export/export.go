@@ -0,0 +1,42 @@
+// Package export
+package export
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/emersonmello/claro/internal"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Export represents the export command
+func Export() *cobra.Command {
+	var format, mappingFile, outputFile string
+	exportCmd := &cobra.Command{
+		Use:   "export <directory-with-student-submissions>",
+		Short: "Export the grades to import them into Moodle, Canvas, Google Classroom or a spreadsheet",
+		Long: tui.LongHelpMsg("Export the grades written in the grade files in the import format of a gradebook.\n" +
+			"Students are matched to their institutional ID and email by a CSV mapping file with the columns login, id, email and name.\n" +
+			"Without --mapping, the file 'students.csv' in the directory or in its parent directory is used; if there is none, one is written listing the students"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New(tui.UseErrorMsg("export"))
+			}
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			return internal.ExportGrades(args[0], format, mappingFile, outputFile, os.Stdout)
+		},
+	}
+	exportCmd.Flags().StringVar(&format, "format", internal.ExportMoodle, "export format: "+strings.Join(internal.ExportFormats, ", "))
+	exportCmd.Flags().StringVar(&mappingFile, "mapping", "", "CSV file mapping the GitHub logins to the students' institutional ID and email")
+	exportCmd.Flags().StringVar(&outputFile, "file", "", "file the grades are written to (default grades-<format>.csv, or grades.xlsx, in the directory)")
+	return exportCmd
+}
@@ -0,0 +1,46 @@
+// Package grade
+package grade
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/emersonmello/claro/internal"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Grade represents the grade command
+func Grade() *cobra.Command {
+	gradeCmd := &cobra.Command{
+		Use:   "grade <directory-with-student-submissions>",
+		Short: "Grade students' submissions interactively",
+		Long:  tui.LongHelpMsg("Grade students' submissions interactively: browse each repository, edit its grade file, enter a score and mark it as reviewed"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New(tui.UseErrorMsg("grade"))
+			}
+			if internal.OutputMode != internal.OutputTUI {
+				return errors.New(i18n.T("grade.tuiRequired"))
+			}
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
+			}
+			m, err := tea.NewProgram(internal.NewGradeModel(args[0]), tea.WithAltScreen()).Run()
+			if err != nil {
+				return fmt.Errorf("%s: %w", i18n.T("summary.tuiFailed"), err)
+			}
+			if m, ok := m.(internal.GradeModel); ok && len(m.SummaryLine()) > 0 {
+				fmt.Println(tui.DoneStyle.Render(m.SummaryLine()))
+			}
+			return nil
+		},
+	}
+	return gradeCmd
+}
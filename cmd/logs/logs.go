@@ -0,0 +1,36 @@
+// Package logs
+package logs
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/emersonmello/claro/internal"
+	"github.com/spf13/cobra"
+)
+
+// Logs represents the logs command
+func Logs() *cobra.Command {
+	var path bool
+	logsCmd := &cobra.Command{
+		Use:   "logs",
+		Short: "Show the log of claro's last run",
+		Long: "Show the log of claro's last run, with the git commands it ran, how long they took, their exit codes and what they wrote to stderr.\n" +
+			fmt.Sprintf("The logs of the last runs are kept in %s", internal.LogDir()),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			if path {
+				fmt.Println(internal.LogDir())
+				return nil
+			}
+			return internal.ShowLastLog(os.Stdout)
+		},
+	}
+	logsCmd.Flags().BoolVar(&path, "path", false, "print the directory where the logs are kept")
+	return logsCmd
+}
@@ -7,9 +7,8 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 
 import (
 	"errors"
-	"fmt"
+	"os"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/emersonmello/claro/internal"
 	"github.com/emersonmello/claro/internal/tui"
 	"github.com/spf13/cobra"
@@ -26,10 +25,22 @@ func Pull() *cobra.Command {
 			if len(args) < 1 {
 				return errors.New(tui.UseErrorMsg("pull"))
 			}
-			if _, err := tea.NewProgram(internal.NewPullModel(args[0])).Run(); err != nil {
-				fmt.Println("Error running program:", err)
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
 			}
-			return nil
+			// From here on, errors are about the repositories, not about how the command was used
+			cmd.SilenceUsage = true
+			if internal.UsesNativeGit() {
+				token, err := internal.GetAndSaveToken()
+				if err != nil {
+					return err
+				}
+				tui.UserGitHubPAT = token
+			}
+			if internal.OutputMode != internal.OutputTUI {
+				return internal.RunPull(args[0], internal.NewReporter(os.Stdout))
+			}
+			return internal.RunTUI(internal.NewPullModel(args[0]))
 		},
 	}
 	return pullCmd
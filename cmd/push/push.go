@@ -7,9 +7,8 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 
 import (
 	"errors"
-	"fmt"
+	"os"
 
-	tea "github.com/charmbracelet/bubbletea"
 	"github.com/emersonmello/claro/internal"
 	"github.com/emersonmello/claro/internal/tui"
 	"github.com/spf13/cobra"
@@ -17,20 +16,41 @@ import (
 
 // Push represents the push command
 func Push() *cobra.Command {
+	var resume bool
+	var grader string
 	pushCmd := &cobra.Command{
 		Use:   "push <directory-with-student-submissions>",
 		Short: "Add, commit, and push the grading file to each student's remote repository",
-		Long:  tui.LongHelpMsg("Use this command to add, commit, and push the grading file for each student's repository to the remote repository"),
+		Long: tui.LongHelpMsg("Use this command to add, commit, and push the grading file for each student's repository to the remote repository.\n" +
+			"With the review delivery, pushing again updates the text of the review posted before. Its inline comments can't be edited, so the annotations are then listed in the review's text"),
 		//Args: cobra.MinimumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) < 1 {
 				return errors.New(tui.UseErrorMsg("push"))
 			}
-			if _, err := tea.NewProgram(internal.NewPushModel(args[0])).Run(); err != nil {
-				fmt.Println("Error running program:", err)
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
 			}
-			return nil
+			if grader != "" {
+				if err := internal.SetGrader(grader); err != nil {
+					return err
+				}
+			}
+			cmd.SilenceUsage = true
+			if internal.UsesNativeGit() || internal.DeliveryUsesAPI() && !tui.GitHubCliInstalled {
+				token, err := internal.GetAndSaveToken()
+				if err != nil {
+					return err
+				}
+				tui.UserGitHubPAT = token
+			}
+			if internal.OutputMode != internal.OutputTUI {
+				return internal.RunPush(args[0], resume, internal.NewReporter(os.Stdout))
+			}
+			return internal.RunTUI(internal.NewPushModel(args[0], resume))
 		},
 	}
+	pushCmd.Flags().BoolVar(&resume, "resume", false, "continue the interrupted push, leaving out the repositories it already graded")
+	pushCmd.Flags().StringVar(&grader, "grader", "", "the grader named in the Co-authored-by trailer of the grading commits, as 'Name <email>' (default is the grader setting)")
 	return pushCmd
 }
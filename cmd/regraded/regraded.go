@@ -0,0 +1,42 @@
+// Package regraded
+package regraded
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+
+	"github.com/emersonmello/claro/internal"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// RegradedSince represents the regraded-since command
+func RegradedSince() *cobra.Command {
+	regradedCmd := &cobra.Command{
+		Use:   "regraded-since <directory-with-student-submissions>",
+		Short: "List the students' repositories with commits made after the graded commit",
+		Long: tui.LongHelpMsg("List the students' repositories with commits made after the commit tagged as graded by the push command, when the gradetag setting is on.\n" +
+			"Commits that only change the grading file are left out. Run the pull command first to get the students' latest commits"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New(tui.UseErrorMsg("regraded-since"))
+			}
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
+			}
+			// The tags are compared with git, whatever the git backend
+			if _, err := exec.LookPath("git"); err != nil {
+				return errors.New(i18n.T("git.required", "regraded-since"))
+			}
+			cmd.SilenceUsage = true
+			return internal.RegradedSince(args[0], os.Stdout)
+		},
+	}
+	return regradedCmd
+}
@@ -14,16 +14,28 @@ import (
 
 	"github.com/emersonmello/claro/cmd/clone"
 	"github.com/emersonmello/claro/cmd/config"
+	"github.com/emersonmello/claro/cmd/contributions"
+	"github.com/emersonmello/claro/cmd/diff"
+	"github.com/emersonmello/claro/cmd/export"
+	"github.com/emersonmello/claro/cmd/grade"
+	"github.com/emersonmello/claro/cmd/logs"
 	"github.com/emersonmello/claro/cmd/pull"
 	"github.com/emersonmello/claro/cmd/push"
+	"github.com/emersonmello/claro/cmd/regraded"
+	"github.com/emersonmello/claro/cmd/roster"
 	"github.com/emersonmello/claro/cmd/token"
 	"github.com/emersonmello/claro/internal"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/emersonmello/claro/internal/tui"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var profile string
+var output string
+var gitBackend string
+var debugLog bool
 var pathConfigFile string
 
 var version = "1.0.1"
@@ -52,8 +64,21 @@ var rootCmd = &cobra.Command{
 	Version: programVersion(),
 }
 
+// Execute runs the command given in the command line and prints its error, unless it was already
+// shown to the user
 func Execute() error {
-	return rootCmd.Execute()
+	rootCmd.SilenceErrors = true
+	err := rootCmd.Execute()
+	var e *internal.ExitCodeError
+	if err != nil && !(errors.As(err, &e) && e.Reported) {
+		_, _ = fmt.Fprintln(os.Stderr, "Error:", err)
+	}
+	return err
+}
+
+// ExitCode returns the exit code that reports the error returned by Execute
+func ExitCode(err error) int {
+	return internal.ExitCode(err)
 }
 
 func init() {
@@ -65,6 +90,27 @@ func init() {
 		"config",
 		"",
 		str)
+	rootCmd.PersistentFlags().StringVar(&profile,
+		"profile",
+		"",
+		"configuration profile to use (default is the one set by 'claro config profile use')")
+	rootCmd.MarkFlagsMutuallyExclusive("config", "profile")
+	rootCmd.PersistentFlags().StringVar(&output,
+		"output",
+		"",
+		"output mode: text, json or tui (default is tui when stdout is a terminal, text otherwise)")
+	rootCmd.PersistentFlags().BoolVar(&internal.Verbose,
+		"verbose",
+		false,
+		"print the log of the run to stderr and explain why directories of the submissions directory were ignored")
+	rootCmd.PersistentFlags().BoolVar(&debugLog,
+		"debug",
+		false,
+		"log the GitHub REST API requests and the output of git commands too (implies --verbose)")
+	rootCmd.PersistentFlags().StringVar(&gitBackend,
+		"git-backend",
+		internal.GitBackendExec,
+		"how git operations are performed: exec runs the git command, native uses a built-in git implementation that doesn't need git installed")
 
 	// Add subcommands
 
@@ -74,27 +120,89 @@ func init() {
 	pushCmd := push.Push()
 	pushCmd.Example = fmt.Sprintf("%s %s assignment-01-submissions", rootCmd.CommandPath(), pushCmd.Name())
 
+	diffCmd := diff.Diff()
+	diffCmd.Example = fmt.Sprintf("%s %s assignment-01-submissions", rootCmd.CommandPath(), diffCmd.Name())
+
+	contributionsCmd := contributions.Contributions()
+	contributionsCmd.Example = fmt.Sprintf("%s %s --append assignment-01-submissions", rootCmd.CommandPath(), contributionsCmd.Name())
+
+	exportCmd := export.Export()
+	exportCmd.Example = fmt.Sprintf("%s %s --format moodle --mapping students.csv assignment-01-submissions", rootCmd.CommandPath(), exportCmd.Name())
+
+	gradeCmd := grade.Grade()
+	gradeCmd.Example = fmt.Sprintf("%s %s assignment-01-submissions", rootCmd.CommandPath(), gradeCmd.Name())
+
+	regradedCmd := regraded.RegradedSince()
+	regradedCmd.Example = fmt.Sprintf("%s %s assignment-01-submissions", rootCmd.CommandPath(), regradedCmd.Name())
+
+	rosterCmd := roster.Roster()
+	rosterCmd.Example = fmt.Sprintf("%s %s import classroom_roster.csv\n%s %s missing assignment-01-submissions", rootCmd.CommandPath(), rosterCmd.Name(), rootCmd.CommandPath(), rosterCmd.Name())
+
 	tokenCmd := token.Token()
 	tokenCmd.Example = fmt.Sprintf("%s %s add\n%s %s del", rootCmd.CommandPath(), tokenCmd.Name(), rootCmd.CommandPath(), tokenCmd.Name())
 
 	rootCmd.AddCommand(clone.Clone())
 	rootCmd.AddCommand(config.Config())
+	rootCmd.AddCommand(contributionsCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(exportCmd)
+	rootCmd.AddCommand(gradeCmd)
+	rootCmd.AddCommand(logs.Logs())
 	rootCmd.AddCommand(tokenCmd)
 	rootCmd.AddCommand(pullCmd)
 	rootCmd.AddCommand(pushCmd)
+	rootCmd.AddCommand(regradedCmd)
+	rootCmd.AddCommand(rosterCmd)
 }
 
 // initConfig reads in config file and ENV variables if set.
 func initConfig() {
+	mode, err := internal.DetectOutputMode(output)
+	if err != nil {
+		fmt.Println(tui.ErrorStyle.Render(err.Error()))
+		os.Exit(1)
+	}
+	internal.OutputMode = mode
+	internal.Verbose = internal.Verbose || debugLog
+	internal.SetupLogging(os.Args, internal.Verbose, debugLog)
+	if err = internal.SelectGitBackend(gitBackend); err != nil {
+		fmt.Println(tui.ErrorStyle.Render(err.Error()))
+		os.Exit(1)
+	}
+
+	if profile == "" && cfgFile == "" {
+		if def := internal.DefaultProfile(); def != "" {
+			// A default profile that was removed must not stop every command, including the
+			// 'config profile use' that replaces it, so the main config file is used instead
+			if _, err := internal.ProfileConfigFile(def); err != nil {
+				_, _ = fmt.Fprintln(os.Stderr, tui.ErrorStyle.Render(i18n.T("profile.staleDefault", def)))
+			} else {
+				profile = def
+			}
+		}
+	}
+	createIfMissing := false
 	if cfgFile != "" {
 		// Use config file from the flag.
+		if _, err := os.Stat(cfgFile); err != nil {
+			fmt.Println(tui.ErrorStyle.Render(i18n.T("configFile.notFound", cfgFile)))
+			os.Exit(1)
+		}
 		viper.SetConfigFile(cfgFile)
+	} else if profile != "" {
+		// Use the profile's config file
+		profileFile, err := internal.ProfileConfigFile(profile)
+		if err != nil {
+			fmt.Println(tui.ErrorStyle.Render(err.Error()))
+			os.Exit(1)
+		}
+		internal.ActiveProfile = profile
+		viper.SetConfigFile(profileFile)
 	} else {
-		// Search config in home directory $HOME/.config/claro/config.env
+		// Search config in home directory $HOME/.config/claro/config.{env,yaml,yml,toml}
 		pathConfigFile = internal.ConfigDir()
-		viper.AddConfigPath(pathConfigFile)
-		viper.SetConfigType("env")
-		viper.SetConfigName("config")
+		viper.SetConfigFile(internal.FindConfigFile(pathConfigFile, "config"))
+		createIfMissing = true
 	}
 
 	viper.SetDefault("version", internal.ClaroConfigStrings.Version)
@@ -102,29 +210,54 @@ func initConfig() {
 	viper.SetDefault("filename", internal.ClaroConfigStrings.Filename)
 	viper.SetDefault("title", internal.ClaroConfigStrings.Title)
 	viper.SetDefault("grade", internal.ClaroConfigStrings.Grade)
+	viper.SetDefault("delivery", internal.ClaroConfigStrings.Delivery)
+	viper.SetDefault("label", internal.ClaroConfigStrings.Label)
+	viper.SetDefault("rubric", internal.ClaroConfigStrings.Rubric)
+	viper.SetDefault("host", internal.ClaroConfigStrings.Host)
+	viper.SetDefault("keyring", internal.ClaroConfigStrings.Keyring)
+	viper.SetDefault("cloneroot", internal.ClaroConfigStrings.CloneRoot)
+	viper.SetDefault("language", internal.ClaroConfigStrings.Language)
+	viper.SetDefault("authorname", internal.ClaroConfigStrings.AuthorName)
+	viper.SetDefault("authoremail", internal.ClaroConfigStrings.AuthorEmail)
+	viper.SetDefault("signing", internal.ClaroConfigStrings.Signing)
+	viper.SetDefault("signingkey", internal.ClaroConfigStrings.SigningKey)
+	viper.SetDefault("grader", internal.ClaroConfigStrings.Grader)
+	viper.SetDefault("gradetag", internal.ClaroConfigStrings.GradeTag)
 
-	viper.AutomaticEnv() // read in environment variables that match
+	viper.SetEnvPrefix(internal.EnvPrefix)
+	viper.AutomaticEnv() // read in the CLARO_* environment variables that match
 
 	// If a config file is found, read it in.
-	if err := viper.ReadInConfig(); err != nil {
-		var configFileNotFoundError viper.ConfigFileNotFoundError
-		// Config file not found
-		if errors.As(err, &configFileNotFoundError) {
-			// Creating config directory
-			if _, err = os.Stat(pathConfigFile); os.IsNotExist(err) {
-				if e := os.MkdirAll(pathConfigFile, 0755); e != nil {
-					_, _ = fmt.Fprintln(os.Stderr, err)
-				}
+	err = viper.ReadInConfig()
+	// The language may be set in the config file, so the default strings are localized afterward
+	internal.LocalizeDefaults()
+	if err != nil {
+		if createIfMissing && errors.Is(err, os.ErrNotExist) {
+			// Creating config directory and config file
+			if e := os.MkdirAll(pathConfigFile, 0755); e != nil {
+				_, _ = fmt.Fprintln(os.Stderr, e)
+			}
+			if e := viper.WriteConfig(); e != nil {
+				_, _ = fmt.Fprintln(os.Stderr, e)
+			} else {
+				_, _ = fmt.Fprintln(os.Stderr, i18n.T("configFile.created", viper.ConfigFileUsed()))
 			}
+		} else {
+			_, _ = fmt.Fprintln(os.Stderr, tui.ErrorStyle.Render(i18n.T("configFile.readError", err)))
+			os.Exit(1)
 		}
-		// Creating config file
-		if err = viper.SafeWriteConfig(); err != nil {
-			_, _ = fmt.Fprintln(os.Stderr, err)
+	} else if changes, err := internal.MigrateConfig(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, tui.ErrorStyle.Render(i18n.T("migrate.failed", viper.ConfigFileUsed(), err)))
+		os.Exit(1)
+	} else if len(changes) > 0 {
+		_, _ = fmt.Fprintln(os.Stderr, i18n.T("migrate.migrated", viper.ConfigFileUsed(), internal.ConfigVersion))
+		for _, change := range changes {
+			_, _ = fmt.Fprintf(os.Stderr, "  - %s\n", change)
 		}
 	}
 
 	// unmarshal config and storing it on runtime conf var
-	if err := viper.Unmarshal(internal.ClaroConfigStrings); err != nil {
+	if err = viper.Unmarshal(internal.ClaroConfigStrings); err != nil {
 		_, _ = fmt.Fprintln(os.Stderr, err)
 	}
 
@@ -134,18 +267,21 @@ func initConfig() {
 }
 
 func checkGitAndCredentials() bool {
-	if _, err := exec.LookPath("git"); err != nil {
-		fmt.Println(tui.ErrorStyle.Render("I can't find 'git' command. Please, be sure that 'git' is installed and in the user PATH"))
-		return false
-	}
-	dirname, _ := os.UserHomeDir()
-	cmd := exec.Command("git", "config", "--global", "--get", "credential.helper")
-	cmd.Dir = dirname
-	if out, _ := cmd.Output(); out != nil {
-		if len(out) == 0 {
-			cmd = exec.Command("git", "config", "--global", "--add", "credential.helper", "cache")
-			cmd.Dir = dirname
-			_ = cmd.Run()
+	// The native git backend neither runs git nor uses git's credential storage
+	if !internal.UsesNativeGit() {
+		if _, err := exec.LookPath("git"); err != nil {
+			fmt.Println(tui.ErrorStyle.Render("I can't find 'git' command. Please, be sure that 'git' is installed and in the user PATH, or use --git-backend=native"))
+			return false
+		}
+		dirname, _ := os.UserHomeDir()
+		cmd := exec.Command("git", "config", "--global", "--get", "credential.helper")
+		cmd.Dir = dirname
+		if out, _ := cmd.Output(); out != nil {
+			if len(out) == 0 {
+				cmd = exec.Command("git", "config", "--global", "--add", "credential.helper", "cache")
+				cmd.Dir = dirname
+				_ = cmd.Run()
+			}
 		}
 	}
 	// Checking if you have GitHub CLI installed
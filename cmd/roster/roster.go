@@ -0,0 +1,68 @@
+// Package roster
+package roster
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"os"
+
+	"github.com/emersonmello/claro/internal"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/spf13/cobra"
+)
+
+// Roster represents the roster command
+func Roster() *cobra.Command {
+	rosterCmd := &cobra.Command{
+		Use:   "roster <import|missing>",
+		Short: "Import the course roster and list the students without a submission to grade",
+		Long: tui.LongHelpMsg("The course roster maps the students' GitHub logins to their real name and student ID, so claro shows their names.\n" +
+			"It is kept as 'students.csv' in the course directory, the parent of the submissions directories, where the export command finds it too"),
+	}
+	rosterCmd.AddCommand(&cobra.Command{
+		Use:   "import <roster.csv> [course-directory]",
+		Short: "Import the course roster from a CSV file",
+		Long: "Import the course roster from a CSV file, replacing the roster imported before.\n" +
+			"The roster exported by GitHub Classroom (identifier, github_username, github_id, name) is read, as is a file with the columns login, id, email and name.\n" +
+			"The roster is stored in the course directory, the parent of the submissions directories, which is the clone root directory by default",
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			courseDirectory := ""
+			if len(args) > 1 {
+				courseDirectory = args[1]
+			}
+			cmd.SilenceUsage = true
+			return internal.ImportRoster(args[0], courseDirectory, os.Stdout)
+		},
+	})
+	var zeroGrade bool
+	missingCmd := &cobra.Command{
+		Use:   "missing <directory-with-student-submissions>",
+		Short: "List the students in the roster without a submission to grade",
+		Long: tui.LongHelpMsg("List the students in the roster who haven't linked a GitHub account, haven't accepted the assignment, or haven't pushed any commit beyond the starter code.\n" +
+			"The accepted assignments and their commit count are fetched from GitHub Classroom when the directory was created by the clone command"),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) < 1 {
+				return errors.New(tui.UseErrorMsg("roster missing"))
+			}
+			if err := internal.LoadAssignmentConfig(args[0]); err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+			if !tui.GitHubCliInstalled {
+				token, err := internal.GetAndSaveToken()
+				if err != nil {
+					return err
+				}
+				tui.UserGitHubPAT = token
+			}
+			return internal.RosterMissing(args[0], zeroGrade, os.Stdout)
+		},
+	}
+	missingCmd.Flags().BoolVar(&zeroGrade, "zero-grade", false, "give them a zero grade in their grade files, so the export command includes them")
+	rosterCmd.AddCommand(missingCmd)
+	return rosterCmd
+}
@@ -25,7 +25,7 @@ func Token() *cobra.Command {
 func configureToken(cmd *cobra.Command, args []string) error {
 	switch args[0] {
 	case "add":
-		internal.AddTokenToKeyring()
+		return internal.AddTokenToKeyring()
 	case "del":
 		internal.DeleteTokenFromKeyring()
 	}
@@ -0,0 +1,236 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/github/gh-classroom/pkg/classroom"
+	"github.com/spf13/viper"
+)
+
+// Why a student of the roster has no submission to grade
+const (
+	absentNoLogin     = "no_login"
+	absentNotAccepted = "not_accepted"
+	absentNoCommits   = "no_commits"
+)
+
+// zeroGradeValue is the grade written to the grade files of the students without a submission
+const zeroGradeValue = "0"
+
+// absentStudent is a student of the course roster without a submission to grade
+type absentStudent struct {
+	student
+	// Repository is the student's repository, when they accepted the assignment without pushing to it
+	Repository string `json:"repo,omitempty"`
+	Reason     string `json:"reason"`
+}
+
+// reasonText returns why the student has no submission, as shown in the report
+func (a absentStudent) reasonText() string {
+	switch a.Reason {
+	case absentNoLogin:
+		return i18n.T("absent.noLogin")
+	case absentNotAccepted:
+		return i18n.T("absent.notAccepted")
+	}
+	return i18n.T("absent.noCommits")
+}
+
+// acceptedAssignments returns the accepted assignments of the submissions directory. They are fetched
+// from GitHub Classroom when the manifest records the assignment, so students who accepted after the
+// clone are found and the commits they pushed are counted; otherwise they are read from the repositories
+// in the directory, without their commit count.
+func acceptedAssignments(directory string) ([]classroom.AcceptedAssignment, bool, error) {
+	if m, err := loadManifest(directory); err == nil && m.Assignment.Id != 0 {
+		accepted, err := fetchAcceptedAssignments(strconv.Itoa(m.Assignment.Id), 0, 0)
+		return accepted, true, err
+	}
+	s, err := scanSubmissions(directory)
+	if err != nil {
+		return nil, false, err
+	}
+	var accepted []classroom.AcceptedAssignment
+	for _, entry := range s.repositories {
+		a := classroom.AcceptedAssignment{Repository: classroom.GithubRepository{Name: entry.Name()}}
+		for _, login := range strings.Split(repositoryResult("", filepath.Join(directory, entry.Name())).Student, ",") {
+			a.Students = append(a.Students, classroom.Student{Login: login})
+		}
+		accepted = append(accepted, a)
+	}
+	return accepted, false, nil
+}
+
+// absentStudents returns the students of the course roster who haven't linked a GitHub account, haven't
+// accepted the assignment, or haven't pushed any commit beyond the starter code, as counted by GitHub
+// Classroom
+func absentStudents(directory string) ([]absentStudent, int, error) {
+	path := findStudentMapping(directory)
+	if path == "" {
+		return nil, 0, errors.New(i18n.T("roster.none"))
+	}
+	roster, err := readStudents(path)
+	if err != nil {
+		return nil, 0, err
+	}
+	accepted, counted, err := acceptedAssignments(directory)
+	if err != nil {
+		return nil, 0, err
+	}
+	repositories := make(map[string]classroom.AcceptedAssignment)
+	for _, a := range accepted {
+		for _, s := range a.Students {
+			repositories[strings.ToLower(s.Login)] = a
+		}
+	}
+	var absent []absentStudent
+	for _, s := range roster {
+		a, ok := repositories[strings.ToLower(s.Login)]
+		switch {
+		case s.Login == "":
+			absent = append(absent, absentStudent{student: s, Reason: absentNoLogin})
+		case !ok:
+			absent = append(absent, absentStudent{student: s, Reason: absentNotAccepted})
+		case counted && a.CommitCount == 0:
+			absent = append(absent, absentStudent{student: s, Repository: a.Repository.Name, Reason: absentNoCommits})
+		}
+	}
+	return absent, len(roster), nil
+}
+
+// zeroGradeFile returns the grade file of a student without a submission: the grade file of their
+// repository, or one named after their login, ID or email when they have no repository. It returns ""
+// if the student can't be identified.
+func zeroGradeFile(directory string, a absentStudent) string {
+	if a.Repository != "" {
+		return filepath.Join(directory, gradeFilename(a.Repository))
+	}
+	m, _ := loadManifest(directory)
+	slug := m.Assignment.Slug
+	if slug == "" {
+		slug = strings.TrimSuffix(filepath.Base(directory), "-submissions")
+	}
+	for _, name := range []string{a.Login, a.ID, a.Email} {
+		if name != "" {
+			return filepath.Join(directory, gradeFilename(slug+"-"+name))
+		}
+	}
+	return ""
+}
+
+// writeZeroGrade gives a zero grade to a student without a submission, in their grade file. A grade
+// file without one is created, with the student's name and why they have no submission in its header;
+// a grade already written is kept, so the grader's decision is never overwritten.
+func writeZeroGrade(path string, a absentStudent) (bool, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		header := "# " + viper.GetString("title") + "\n"
+		if a.Name != "" {
+			header += "> " + i18n.T("defaults.student") + ": " + studentIdentities([]student{a.student}) + "\n"
+		}
+		header += "> " + a.reasonText() + "\n\n"
+		if err = os.WriteFile(path, []byte(header), 0644); err != nil {
+			return false, err
+		}
+	}
+	if grade, err := readGradeValue(path); err != nil || grade != "" {
+		return false, err
+	}
+	return true, writeGradeValue(path, zeroGradeValue)
+}
+
+// zeroGradePlaceholder reports whether the grade file is the zero grade of a student who hadn't accepted
+// the assignment: it has no graded commit in its header, as no repository was cloned, and a zero grade.
+// The grade file of the repository replaces it once the student accepts the assignment.
+func zeroGradePlaceholder(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		if gradedCommitPattern.MatchString(line) {
+			return false
+		}
+	}
+	grade, err := readGradeValue(path)
+	return err == nil && grade == zeroGradeValue
+}
+
+// writeZeroGrades gives a zero grade to the students without a submission, returning how many grade
+// files were written
+func writeZeroGrades(directory string, absent []absentStudent) (int, error) {
+	written := 0
+	for _, a := range absent {
+		path := zeroGradeFile(directory, a)
+		if path == "" {
+			continue
+		}
+		ok, err := writeZeroGrade(path, a)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", filepath.Base(path), err)
+		}
+		if ok {
+			written++
+		}
+	}
+	return written, nil
+}
+
+// RosterMissing writes the students of the course roster without a submission to grade: those who
+// haven't linked a GitHub account to the roster, haven't accepted the assignment, or haven't pushed any
+// commit to their repository. With zeroGrade, they are given a zero grade in their grade files, so the
+// export command includes them.
+func RosterMissing(directory string, zeroGrade bool, w io.Writer) error {
+	directory, _ = filepath.Abs(expandHomeDirectory(directory))
+	absent, total, err := absentStudents(directory)
+	if err != nil {
+		return err
+	}
+	written := 0
+	if zeroGrade {
+		if written, err = writeZeroGrades(directory, absent); err != nil {
+			return err
+		}
+	}
+	if OutputMode == OutputJSON {
+		encoder := json.NewEncoder(w)
+		for _, a := range absent {
+			if err = encoder.Encode(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if len(absent) == 0 {
+		_, _ = fmt.Fprintln(w, i18n.T("roster.allAccepted", total))
+		return nil
+	}
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, a := range absent {
+		login := "@" + a.Login
+		if a.Login == "" {
+			login = "-"
+		}
+		_, _ = fmt.Fprintf(t, "%s\t%s\t%s\t%s\t%s\n", a.Name, a.ID, a.Email, login, a.reasonText())
+	}
+	if err = t.Flush(); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, i18n.T("roster.missing", len(absent), total))
+	if zeroGrade {
+		_, _ = fmt.Fprintln(w, i18n.T("absent.zeroGrades", written))
+	}
+	return nil
+}
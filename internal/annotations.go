@@ -0,0 +1,170 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+)
+
+const annotationContextLines = 2
+
+var (
+	// Annotations are written in the grade file as "@src/main.c:42: off-by-one here"
+	annotationPattern = regexp.MustCompile(`^\s*(?:[-*]\s+)?@([^\s:]+):(\d+):\s*(.*)$`)
+	// The commit graded is recorded in the grade file's header as "> Commit: 1a2b3c4 | 2024-01-01 10:00:00 -0300"
+	gradedCommitPattern = regexp.MustCompile(`^>\s*Commit:\s*([0-9a-fA-F]+)`)
+	// hunkPattern matches the header of a diff hunk, "@@ -10,7 +12,8 @@", capturing its first line in the new file
+	hunkPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+)
+
+// annotation is a grader's comment about a line of a file in the student's repository
+type annotation struct {
+	Path    string
+	Line    int
+	Comment string
+	// lines holds the file's content at the graded commit
+	lines []string
+}
+
+// feedback is the content delivered to the student, built from the grade file
+type feedback struct {
+	// body is the grade file without its annotations
+	body string
+	// gradedCommit is the commit recorded in the grade file's header, and commit its full hash, resolved
+	// only when there are annotations
+	gradedCommit string
+	commit       string
+	annotations  []annotation
+}
+
+// readFeedback parses the grade file and resolves its annotations against the graded commit of the
+// repository. Annotations pointing at files or lines that don't exist in that commit are reported as errors.
+func readFeedback(directory string, gradeFile string) (feedback, error) {
+	var f feedback
+	content, err := os.ReadFile(gradeFile)
+	if err != nil {
+		return f, err
+	}
+	var body []string
+	commit := "HEAD"
+	for _, line := range strings.Split(string(content), "\n") {
+		if match := gradedCommitPattern.FindStringSubmatch(line); match != nil && commit == "HEAD" {
+			commit, f.gradedCommit = match[1], match[1]
+		}
+		if match := annotationPattern.FindStringSubmatch(line); match != nil {
+			var a annotation
+			a.Path, a.Comment = match[1], match[3]
+			_, _ = fmt.Sscanf(match[2], "%d", &a.Line)
+			f.annotations = append(f.annotations, a)
+			continue
+		}
+		body = append(body, line)
+	}
+	f.body = strings.Join(body, "\n")
+	if len(f.annotations) == 0 {
+		return f, nil
+	}
+
+	f.commit, err = gitBackend.ResolveCommit(directory, commit)
+	if err != nil {
+		return f, errors.New(i18n.T("git.gradedCommitNotFound", commit))
+	}
+
+	var errs []error
+	for i, a := range f.annotations {
+		out, err := gitBackend.FileAt(directory, f.commit, a.Path)
+		if err != nil {
+			errs = append(errs, errors.New(i18n.T("feedback.annotationFileNotFound", a.Path, a.Line, f.commit)))
+			continue
+		}
+		lines := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+		if a.Line < 1 || a.Line > len(lines) {
+			errs = append(errs, errors.New(i18n.T("feedback.annotationLineOutOfRange", a.Path, a.Line, len(lines))))
+			continue
+		}
+		f.annotations[i].lines = lines
+	}
+	if len(errs) > 0 {
+		return f, fmt.Errorf("%s\n%w", i18n.T("feedback.invalidAnnotations", filepath.Base(gradeFile)), errors.Join(errs...))
+	}
+	return f, nil
+}
+
+// diffLines returns the lines of the new version of a file that a pull request review can comment on:
+// the added and the context lines of the file's patch
+func diffLines(patch string) map[int]bool {
+	lines := make(map[int]bool)
+	n := 0
+	for _, line := range strings.Split(patch, "\n") {
+		if match := hunkPattern.FindStringSubmatch(line); match != nil {
+			n, _ = strconv.Atoi(match[1])
+			continue
+		}
+		// Removed lines and "\ No newline at end of file" are not in the new version
+		if n > 0 && (strings.HasPrefix(line, "+") || strings.HasPrefix(line, " ")) {
+			lines[n] = true
+			n++
+		}
+	}
+	return lines
+}
+
+// review returns the body and the inline comments of a pull request review. Annotations on the lines
+// of the pull request's diff become inline comments, while the others are listed in the body, as GitHub
+// rejects the whole review when one of its comments is outside the diff.
+func (f feedback) review(commentable map[string]map[int]bool) (string, []reviewComment) {
+	var comments []reviewComment
+	var listed []annotation
+	for _, a := range f.annotations {
+		if commentable[a.Path][a.Line] {
+			comments = append(comments, reviewComment{Path: a.Path, Line: a.Line, Body: a.Comment})
+		} else {
+			listed = append(listed, a)
+		}
+	}
+	return annotationListing(f.body, listed), comments
+}
+
+// annotatedListing returns the grade file with an appended listing of the annotated lines,
+// for when the feedback is not delivered as a pull request review
+func (f feedback) annotatedListing() string {
+	return annotationListing(f.body, f.annotations)
+}
+
+// annotationListing returns the body with an appended listing of the annotated lines, each one shown
+// with the lines around it
+func annotationListing(body string, annotations []annotation) string {
+	if len(annotations) == 0 {
+		return body
+	}
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(body, "\n"))
+	b.WriteString("\n\n## " + i18n.T("feedback.annotations") + "\n")
+	for _, a := range annotations {
+		b.WriteString(fmt.Sprintf("\n**%s:%d** — %s\n\n", a.Path, a.Line, a.Comment))
+		b.WriteString("```" + strings.TrimPrefix(filepath.Ext(a.Path), ".") + "\n")
+		first := max(1, a.Line-annotationContextLines)
+		last := min(len(a.lines), a.Line+annotationContextLines)
+		width := len(fmt.Sprintf("%d", last))
+		for n := first; n <= last; n++ {
+			marker := " "
+			if n == a.Line {
+				marker = ">"
+			}
+			b.WriteString(fmt.Sprintf("%s %*d | %s\n", marker, width, n, a.lines[n-1]))
+		}
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
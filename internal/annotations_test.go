@@ -0,0 +1,146 @@
+package internal
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/emersonmello/claro/internal/i18n"
+)
+
+func TestAnnotationPattern(t *testing.T) {
+	tests := map[string][]string{
+		"@src/main.c:42: off-by-one here":  {"src/main.c", "42", "off-by-one here"},
+		"- @main.c:1: unused variable":     {"main.c", "1", "unused variable"},
+		"  * @lib/util.go:7:missing check": {"lib/util.go", "7", "missing check"},
+		"@main.c:3:":                       {"main.c", "3", ""},
+		"@main.c: no line":                 nil,
+		"email me@example.edu:10: later":   nil,
+		"- **Grade: 10**":                  nil,
+	}
+	for line, want := range tests {
+		var got []string
+		if match := annotationPattern.FindStringSubmatch(line); match != nil {
+			got = match[1:]
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("annotationPattern(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestGradedCommitPattern(t *testing.T) {
+	tests := map[string]string{
+		"> Commit: 1a2b3c4 | 2024-01-01 10:00:00 -0300": "1a2b3c4",
+		">Commit:ABCDEF0":                 "ABCDEF0",
+		"> Commit: not-a-hash":            "",
+		"Commit: 1a2b3c4":                 "",
+		"> Student: Ana Lima (2024001)":   "",
+		"> Commit: 1a2b3c4d5e6f7a8b9c0d1": "1a2b3c4d5e6f7a8b9c0d1",
+	}
+	for line, want := range tests {
+		got := ""
+		if match := gradedCommitPattern.FindStringSubmatch(line); match != nil {
+			got = match[1]
+		}
+		if got != want {
+			t.Errorf("gradedCommitPattern(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestDiffLines(t *testing.T) {
+	tests := map[string]struct {
+		patch string
+		want  []int
+	}{
+		"new file":     {"@@ -0,0 +1,3 @@\n+a\n+b\n+c", []int{1, 2, 3}},
+		"single line":  {"@@ -1 +1 @@\n-# hw\n+# hw-alice", []int{1}},
+		"context":      {"@@ -10,4 +12,4 @@\n a\n-b\n+B\n c\n d", []int{12, 13, 14, 15}},
+		"two hunks":    {"@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -20,2 +20,3 @@\n x\n+y\n z", []int{1, 2, 20, 21, 22}},
+		"no newline":   {"@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+b\n\\ No newline at end of file", []int{1}},
+		"only removed": {"@@ -1,2 +0,0 @@\n-a\n-b", nil},
+		"no patch":     {"", nil},
+	}
+	for name, tt := range tests {
+		var got []int
+		for line := range diffLines(tt.patch) {
+			got = append(got, line)
+		}
+		slices.Sort(got)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: diffLines = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestFeedbackReview(t *testing.T) {
+	f := feedback{
+		body: "# Feedback\n\n- **Grade: 7**\n",
+		annotations: []annotation{
+			{Path: "main.c", Line: 2, Comment: "unused", lines: []string{"int a;", "int b;", "int c;"}},
+			{Path: "README.md", Line: 1, Comment: "title", lines: []string{"# hw"}},
+		},
+	}
+	tests := map[string]struct {
+		commentable map[string]map[int]bool
+		inline      []string
+		listed      []string
+	}{
+		"all inside the diff": {map[string]map[int]bool{"main.c": {2: true}, "README.md": {1: true}}, []string{"main.c:2", "README.md:1"}, nil},
+		"one outside":         {map[string]map[int]bool{"main.c": {2: true}}, []string{"main.c:2"}, []string{"**README.md:1**"}},
+		"other line":          {map[string]map[int]bool{"main.c": {3: true}}, nil, []string{"**main.c:2**", "**README.md:1**"}},
+		"no diff":             {nil, nil, []string{"**main.c:2**", "**README.md:1**"}},
+	}
+	for name, tt := range tests {
+		body, comments := f.review(tt.commentable)
+		var inline []string
+		for _, c := range comments {
+			inline = append(inline, c.Path+":"+strconv.Itoa(c.Line))
+		}
+		if !slices.Equal(inline, tt.inline) {
+			t.Errorf("%s: inline comments = %v, want %v", name, inline, tt.inline)
+		}
+		if listing := strings.Contains(body, "## "+i18n.T("feedback.annotations")); listing != (tt.listed != nil) {
+			t.Errorf("%s: body lists annotations = %v:\n%s", name, listing, body)
+		}
+		for _, listed := range tt.listed {
+			if !strings.Contains(body, listed) {
+				t.Errorf("%s: body doesn't list %s:\n%s", name, listed, body)
+			}
+		}
+	}
+}
+
+func TestAnnotatedListing(t *testing.T) {
+	lines := []string{"l1", "l2", "l3", "l4", "l5", "l6"}
+	tests := map[string]struct {
+		line int
+		want string
+	}{
+		"first line": {1, "```go\n> 1 | l1\n  2 | l2\n  3 | l3\n```\n"},
+		"middle":     {4, "```go\n  2 | l2\n  3 | l3\n> 4 | l4\n  5 | l5\n  6 | l6\n```\n"},
+		"last line":  {6, "```go\n  4 | l4\n  5 | l5\n> 6 | l6\n```\n"},
+	}
+	for name, tt := range tests {
+		f := feedback{body: "# Feedback\n\n", annotations: []annotation{{Path: "main.go", Line: tt.line, Comment: "here", lines: lines}}}
+		want := "# Feedback\n\n## " + i18n.T("feedback.annotations") + "\n\n**main.go:" + strconv.Itoa(tt.line) + "** — here\n\n" + tt.want
+		if got := f.annotatedListing(); got != want {
+			t.Errorf("%s: annotatedListing() = %q, want %q", name, got, want)
+		}
+	}
+
+	if got := (feedback{body: "# Feedback\n"}).annotatedListing(); got != "# Feedback\n" {
+		t.Errorf("annotatedListing() without annotations = %q", got)
+	}
+}
+
+func TestAnnotatedListingLineNumberWidth(t *testing.T) {
+	f := feedback{annotations: []annotation{{Path: "Makefile", Line: 9, Comment: "tab", lines: make([]string, 12)}}}
+	want := "```\n   7 | \n   8 | \n>  9 | \n  10 | \n  11 | \n```\n"
+	if got := f.annotatedListing(); !strings.HasSuffix(got, want) {
+		t.Errorf("annotatedListing() = %q, want it to end with %q", got, want)
+	}
+}
@@ -6,6 +6,7 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/charmbracelet/bubbles/help"
@@ -14,6 +15,7 @@ import (
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/emersonmello/claro/internal/tui"
 	"github.com/github/gh-classroom/pkg/classroom"
 )
@@ -48,10 +50,16 @@ type CloneModel struct {
 	width           int
 	height          int
 	credentialSet   bool
+	assignmentId    string
+	resume          bool
+	journal         *journal
+	summary         Summary
 }
 
-// NewCloneModel creates a new CloneModel
-func NewCloneModel() CloneModel {
+// NewCloneModel creates a new CloneModel. If assignmentId is empty, the classroom and the assignment
+// are selected from lists. If resume is set, the repositories cloned by the interrupted run recorded
+// in the journal are left out.
+func NewCloneModel(assignmentId string, resume bool) CloneModel {
 	styles := tui.CreateDefaultStyles()
 	keys := tui.ClaroKeyMap()
 	h := help.New()
@@ -63,8 +71,12 @@ func NewCloneModel() CloneModel {
 		progress.WithWidth(50),
 		progress.WithoutPercentage(),
 	)
+	st := initial
+	if assignmentId != "" {
+		st = fetchRepositoriesList
+	}
 	return CloneModel{
-		state:         initial,
+		state:         st,
 		styles:        styles,
 		keyMap:        keys,
 		help:          h,
@@ -73,10 +85,15 @@ func NewCloneModel() CloneModel {
 		index:         0,
 		totalCloned:   0,
 		credentialSet: false,
+		assignmentId:  assignmentId,
+		resume:        resume,
 	}
 }
 
 func (m CloneModel) Init() tea.Cmd {
+	if m.assignmentId != "" {
+		return tea.Batch(m.spinner.Tick, restGetAssignmentSubmissions(m.assignmentId))
+	}
 	return tea.Batch(m.spinner.Tick, restGetClassrooms(0))
 }
 
@@ -100,17 +117,17 @@ func (m CloneModel) View() string {
 }
 
 func (m CloneModel) classroomView() string {
-	str := fmt.Sprintf("%s fetching your classrooms", m.spinner.View())
+	str := fmt.Sprintf("%s %s", m.spinner.View(), i18n.T("clone.fetchingClassrooms"))
 	return lipgloss.JoinVertical(lipgloss.Top, lipgloss.NewStyle().MarginLeft(1).Render(str))
 }
 
 func (m CloneModel) assignmentsView() string {
-	str := fmt.Sprintf("%s retrieving your assignments", m.spinner.View())
+	str := fmt.Sprintf("%s %s", m.spinner.View(), i18n.T("clone.retrievingAssignments"))
 	return lipgloss.JoinVertical(lipgloss.Top, lipgloss.NewStyle().MarginLeft(1).Render(str))
 }
 
 func (m CloneModel) acceptedAssignmentsView() string {
-	str := fmt.Sprintf("%s Retrieving accepted assignments.", m.spinner.View())
+	str := fmt.Sprintf("%s %s", m.spinner.View(), i18n.T("clone.retrievingAccepted"))
 	return lipgloss.JoinVertical(lipgloss.Top, lipgloss.NewStyle().MarginLeft(1).Render(str))
 }
 
@@ -119,14 +136,17 @@ func (m CloneModel) cloneView() string {
 	w := lipgloss.Width(fmt.Sprintf("%d", n))
 
 	if m.done {
-		return tui.DoneStyle.Render(fmt.Sprintf("Cloned %d repositories\n", m.totalCloned))
+		return tui.DoneStyle.Render(i18n.T("clone.done", m.totalCloned) + "\n")
 	}
 	count := fmt.Sprintf(" %*d/%*d", w, m.index+1, w, n)
-	per := float64(m.index) / float64(len(m.repoL)-1)
+	per := progressPercent(m.index, n)
 	prog := m.progress.ViewAs(per)
 	cellsAvail := max(0, m.width-lipgloss.Width(prog+count))
 
 	repository := tui.CurrentRepositoryStyle.Render(m.repoL[m.index].Repository.Name)
+	if names := rosterNames(submissionsDirectory(m.repoL[m.index].Assignment), studentLogins(m.repoL[m.index])); names != "" {
+		repository += " " + tui.DetailStyle.Render(names)
+	}
 	info := lipgloss.NewStyle().MaxWidth(cellsAvail).Render(fmt.Sprintf("%s %s ", tui.BowtieMark, repository))
 	newLine := lipgloss.NewStyle().Render("\n")
 	//cellsRemaining := max(0, m.width-lipgloss.Width(spin+info+prog+count)-2)
@@ -226,13 +246,14 @@ func listUpdate(msg tea.Msg, m CloneModel) (tea.Model, tea.Cmd) {
 				keys := tui.ClaroKeyMap()
 				height := min(len(msg)+8, m.height) - 2
 				l := list.New(tui.MakeClassroomList(m.cL), tui.NewItemDelegate(&styles, keys), tui.DefaultWidth, height)
-				l = tui.FormatList(l, "Select a classroom")
+				l = tui.FormatList(l, i18n.T("clone.selectClassroom"))
 				l.AdditionalShortHelpKeys = m.keyMap.ShortHelp
 				m.classroomList = l
 				return m, nil
 			}
 		}
-		return m, tea.Sequence(tea.Printf(m.styles.QuitText.Render("You don't have GitHub Classrooms, or you do not have permission to access them.")), tea.Quit)
+		m.summary.Err = errors.New(i18n.T("clone.noClassrooms"))
+		return m, tea.Sequence(tea.Printf(m.styles.QuitText.Render(i18n.T("clone.noClassrooms"))), tea.Quit)
 	case tui.AssignmentsList:
 		m.aL = msg
 		if m.aL != nil {
@@ -242,49 +263,97 @@ func listUpdate(msg tea.Msg, m CloneModel) (tea.Model, tea.Cmd) {
 				keys := tui.ClaroKeyMap()
 				height := min(len(msg)+8, m.height) - 2
 				l := list.New(tui.MakeAssignmentsList(m.aL), tui.NewItemDelegate(&styles, keys), tui.DefaultWidth, height)
-				l = tui.FormatList(l, "Select an assignment")
+				l = tui.FormatList(l, i18n.T("clone.selectAssignment"))
 				l.AdditionalShortHelpKeys = m.keyMap.ShortHelp
 				m.assignmentsList = l
 				return m, nil
 			}
 		}
-		return m, tea.Sequence(tea.Printf(m.styles.QuitText.Render("No assignments were found for this classroom, or you do not have permission to access them.")), tea.Quit)
+		m.summary.Err = errors.New(i18n.T("clone.noAssignments"))
+		return m, tea.Sequence(tea.Printf(m.styles.QuitText.Render(i18n.T("clone.noAssignments"))), tea.Quit)
+	case assignmentSubmissions:
+		m.aL = tui.AssignmentsList{msg.assignment}
+		return m.startCloning(msg.accepted)
 	case []classroom.AcceptedAssignment:
-		m.repoL = msg
-		if len(m.repoL) > 0 {
-			m.state = cloningAssignment
-			m.index = 0
-			return m, tea.Sequence(tea.Printf("Found %d repositories. Cloning...\n", len(m.repoL)), gitCloneAssignment(m.repoL[m.index]), m.spinner.Tick)
-		}
-		return m, tea.Sequence(tea.Printf(m.styles.QuitText.Render("No student submissions were found for this assignment, or you do not have permission to access them.")), tea.Quit)
+		return m.startCloning(msg)
 	case tui.ErrorMsg:
+		m.summary.Err = errors.New(string(msg))
+		return m, tea.Sequence(tea.Printf(m.styles.ErrorText.Render(string(msg))), tea.Quit)
+	case tui.AuthErrorMsg:
+		m.summary.Err = authError(msg)
 		return m, tea.Sequence(tea.Printf(m.styles.ErrorText.Render(string(msg))), tea.Quit)
 	}
 	return m, nil
 }
 
+// startCloning records the assignment's manifest and starts cloning the accepted assignments
+func (m CloneModel) startCloning(accepted []classroom.AcceptedAssignment) (tea.Model, tea.Cmd) {
+	m.repoL = accepted
+	if len(m.repoL) > 0 {
+		m.state = cloningAssignment
+		m.index = 0
+		directory := submissionsDirectory(m.repoL[0].Assignment)
+		if err := LoadAssignmentConfig(directory); err != nil {
+			m.summary.Err = err
+			return m, tea.Sequence(tea.Printf(m.styles.ErrorText.Render(err.Error())), tea.Quit)
+		}
+		found := tea.Printf("%s\n", i18n.T("clone.found", len(m.repoL)))
+		if err := updateManifest(directory, m.selectedAssignment(), m.repoL); err != nil {
+			found = tea.Sequence(found, tea.Printf(m.styles.ErrorText.Render(i18n.T("clone.manifestError", err))))
+		}
+		m.journal = openJournal(directory)
+		if err := m.journal.begin(actionClone, m.resume); err != nil {
+			found = tea.Sequence(found, tea.Printf(m.styles.ErrorText.Render(i18n.T("journal.saveError", err))))
+		}
+		m.repoL = pendingAssignments(m.journal, accepted)
+		if message := resumeMessage(len(accepted), len(m.repoL)); message != "" {
+			found = tea.Sequence(found, tea.Printf("%s\n", message))
+		}
+		if len(m.repoL) == 0 {
+			m.done = true
+			return m, tea.Sequence(found, tea.Quit)
+		}
+		return m, tea.Sequence(found, m.journal.clone(m.repoL[m.index]).cmd(), m.spinner.Tick)
+	}
+	m.summary.Err = errors.New(i18n.T("clone.noSubmissions"))
+	return m, tea.Sequence(tea.Printf(m.styles.QuitText.Render(i18n.T("clone.noSubmissions"))), tea.Quit)
+}
+
+// Summary returns the results of the cloned repositories, or the error that stopped the command
+func (m CloneModel) Summary() Summary {
+	return m.summary
+}
+
+// selectedAssignment returns the assignment being cloned as listed by the assignments endpoint,
+// which is more complete than the copy embedded in each accepted assignment
+func (m CloneModel) selectedAssignment() classroom.Assignment {
+	a := m.repoL[0].Assignment
+	for _, assignment := range m.aL {
+		if assignment.Id == a.Id {
+			return assignment
+		}
+	}
+	return a
+}
+
 func cloneUpdate(msg tea.Msg, m CloneModel) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.width, m.height = msg.Width, msg.Height
-	case tui.SuccessfullMsg:
-		m.totalCloned++
-		cmd := tea.Printf("%s %s", tui.CheckMark, m.repoL[m.index].Repository.Name)
-		if m.index >= len(m.repoL)-1 {
-			m.done = true
-			return m, tea.Sequence(cmd, tea.Quit)
+	case Result:
+		m.journal.record(actionClone, msg)
+		m.summary.add(msg)
+		if msg.Status == StatusSucceeded {
+			m.totalCloned++
 		}
-		m.index++
-		return m, tea.Sequence(cmd, gitCloneAssignment(m.repoL[m.index]))
-	case tui.ErrorMsg:
-		reason := lipgloss.NewStyle().Foreground(lipgloss.Color("#783D38")).Italic(true).SetString(string(msg))
-		cmd := tea.Printf("%s %s %s", tui.ErrorMark, m.repoL[m.index].Repository.Name, reason)
+		cmd := resultLine(msg)
 		if m.index >= len(m.repoL)-1 {
+			m.journal.finish(actionClone)
 			m.done = true
 			return m, tea.Sequence(cmd, tea.Quit)
 		}
 		m.index++
-		return m, tea.Sequence(cmd, gitCloneAssignment(m.repoL[m.index]))
+		return m, tea.Sequence(cmd, m.journal.clone(m.repoL[m.index]).cmd())
 	case spinner.TickMsg:
 		var cmd tea.Cmd
 		m.spinner, cmd = m.spinner.Update(msg)
@@ -6,12 +6,20 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
 	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/charmbracelet/huh"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -21,11 +29,26 @@ const (
 )
 
 type ClaroCfg struct {
-	Version  int    `mapstructure:"version"`
-	Message  string `mapstructure:"message"`
-	Filename string `mapstructure:"filename"`
-	Title    string `mapstructure:"title"`
-	Grade    string `mapstructure:"grade"`
+	Version   int    `mapstructure:"version"`
+	Message   string `mapstructure:"message"`
+	Filename  string `mapstructure:"filename"`
+	Title     string `mapstructure:"title"`
+	Grade     string `mapstructure:"grade"`
+	Delivery  string `mapstructure:"delivery"`
+	Label     string `mapstructure:"label"`
+	Rubric    string `mapstructure:"rubric"`
+	Host      string `mapstructure:"host"`
+	Keyring   string `mapstructure:"keyring"`
+	CloneRoot string `mapstructure:"cloneroot"`
+	Language  string `mapstructure:"language"`
+	// The author, signature and co-author of the grading commits
+	AuthorName  string `mapstructure:"authorname"`
+	AuthorEmail string `mapstructure:"authoremail"`
+	Signing     string `mapstructure:"signing"`
+	SigningKey  string `mapstructure:"signingkey"`
+	Grader      string `mapstructure:"grader"`
+	// GradeTag tags the graded commit when the feedback is delivered
+	GradeTag bool `mapstructure:"gradetag"`
 }
 type choice int
 
@@ -34,11 +57,31 @@ const (
 	message
 	title
 	grade
+	delivery
+	label
+	rubric
+	host
+	cloneRoot
+	language
 	quit
 )
 
+// Feedback delivery modes
+const (
+	deliveryCommit  = "commit"
+	deliveryReview  = "review"
+	deliveryComment = "comment"
+	deliveryIssue   = "issue"
+)
+
 const configFilename = "claro"
 
+// EnvPrefix prefixes the environment variables that override the configuration keys, such as CLARO_HOST
+const EnvPrefix = "CLARO"
+
+// assignmentConfigFilename is the per-assignment config file, looked up in the submissions directory and in its parent course directory
+const assignmentConfigFilename = ".claro.yaml"
+
 func ConfigDir() string {
 	var path string
 
@@ -54,11 +97,27 @@ func ConfigDir() string {
 }
 
 var ClaroConfigStrings = &ClaroCfg{
-	Version:  1,
-	Message:  "This project has been graded. The file containing the grade is located in the root directory.",
+	Version:  ConfigVersion,
+	Message:  i18n.T("defaults.message"),
 	Filename: "GRADING.md",
-	Title:    "Feedback",
-	Grade:    "Grade: ",
+	Title:    i18n.T("defaults.title"),
+	Grade:    i18n.T("defaults.grade"),
+	Delivery: deliveryCommit,
+	Label:    "graded",
+	Host:     "github.com",
+	Keyring:  configFilename,
+}
+
+// LocalizeDefaults selects the language of claro's messages, from the config file or the user's locale,
+// and translates the default strings of the grade file and of the commit message accordingly
+func LocalizeDefaults() {
+	i18n.SetLanguage(i18n.Detect(viper.GetString("language")))
+	ClaroConfigStrings.Message = i18n.T("defaults.message")
+	ClaroConfigStrings.Title = i18n.T("defaults.title")
+	ClaroConfigStrings.Grade = i18n.T("defaults.grade")
+	viper.SetDefault("message", ClaroConfigStrings.Message)
+	viper.SetDefault("title", ClaroConfigStrings.Title)
+	viper.SetDefault("grade", ClaroConfigStrings.Grade)
 }
 
 func ConfigCmd(cmd *cobra.Command, args []string) error {
@@ -70,20 +129,26 @@ func ConfigCmd(cmd *cobra.Command, args []string) error {
 		form := huh.NewForm(
 			huh.NewGroup(
 				huh.NewSelect[choice]().
-					Title("Which option do you want to configure?").
+					Title(i18n.T("config.menu")).
 					Options(
-						huh.NewOption("Grade filename", filename),
-						huh.NewOption("Commit message", message),
-						huh.NewOption("Grade file's title", title),
-						huh.NewOption("Grade file's grade string", grade),
-						huh.NewOption("Quit", quit),
+						huh.NewOption(i18n.T("config.filename"), filename),
+						huh.NewOption(i18n.T("config.message"), message),
+						huh.NewOption(i18n.T("config.title"), title),
+						huh.NewOption(i18n.T("config.grade"), grade),
+						huh.NewOption(i18n.T("config.delivery"), delivery),
+						huh.NewOption(i18n.T("config.label"), label),
+						huh.NewOption(i18n.T("config.rubric"), rubric),
+						huh.NewOption(i18n.T("config.host"), host),
+						huh.NewOption(i18n.T("config.cloneRoot"), cloneRoot),
+						huh.NewOption(i18n.T("config.language"), language),
+						huh.NewOption(i18n.T("config.quit"), quit),
 					).
 					Value(&option),
 			),
 		)
 
 		if err := form.Run(); err != nil {
-			fmt.Println("There was an error running the program:", err)
+			fmt.Println(i18n.T("config.runError"), err)
 		}
 
 		var group *huh.Group
@@ -93,25 +158,74 @@ func ConfigCmd(cmd *cobra.Command, args []string) error {
 			group = huh.NewGroup(
 				huh.NewInput().
 					Value(&ClaroConfigStrings.Filename).
-					Title("The name of the file that will be created in the student repository containing the feedback."),
+					Title(i18n.T("config.filenameHelp")),
 			)
 		case message:
 			group = huh.NewGroup(
 				huh.NewInput().
 					Value(&ClaroConfigStrings.Message).
-					Title("Commit message for grading"),
+					Validate(checkMessageTemplate).
+					Title(i18n.T("config.messageHelp")),
 			)
 		case title:
 			group = huh.NewGroup(
 				huh.NewInput().
 					Value(&ClaroConfigStrings.Title).
-					Title("The file's title representing the grade sheet"),
+					Title(i18n.T("config.titleHelp")),
 			)
 		case grade:
 			group = huh.NewGroup(
 				huh.NewInput().
 					Value(&ClaroConfigStrings.Grade).
-					Title("The grade string inserted in the file representing the grade sheet."),
+					Title(i18n.T("config.gradeHelp")),
+			)
+		case delivery:
+			group = huh.NewGroup(
+				huh.NewSelect[string]().
+					Options(
+						huh.NewOption(i18n.T("config.deliveryCommit"), deliveryCommit),
+						huh.NewOption(i18n.T("config.deliveryReview"), deliveryReview),
+						huh.NewOption(i18n.T("config.deliveryComment"), deliveryComment),
+						huh.NewOption(i18n.T("config.deliveryIssue"), deliveryIssue),
+					).
+					Value(&ClaroConfigStrings.Delivery).
+					Title(i18n.T("config.deliveryHelp")),
+			)
+		case label:
+			group = huh.NewGroup(
+				huh.NewInput().
+					Value(&ClaroConfigStrings.Label).
+					Title(i18n.T("config.labelHelp")),
+			)
+		case rubric:
+			group = huh.NewGroup(
+				huh.NewInput().
+					Value(&ClaroConfigStrings.Rubric).
+					Title(i18n.T("config.rubricHelp")),
+			)
+		case host:
+			group = huh.NewGroup(
+				huh.NewInput().
+					Value(&ClaroConfigStrings.Host).
+					Title(i18n.T("config.hostHelp")),
+			)
+		case cloneRoot:
+			group = huh.NewGroup(
+				huh.NewInput().
+					Value(&ClaroConfigStrings.CloneRoot).
+					Title(i18n.T("config.cloneRootHelp")),
+			)
+		case language:
+			group = huh.NewGroup(
+				huh.NewSelect[string]().
+					Options(
+						huh.NewOption(i18n.T("config.languageAutomatic"), ""),
+						huh.NewOption("English", i18n.English),
+						huh.NewOption("Português (Brasil)", i18n.Portuguese),
+						huh.NewOption("Español", i18n.Spanish),
+					).
+					Value(&ClaroConfigStrings.Language).
+					Title(i18n.T("config.languageHelp")),
 			)
 		case quit:
 			// Saving config file
@@ -119,6 +233,12 @@ func ConfigCmd(cmd *cobra.Command, args []string) error {
 			viper.Set("Message", ClaroConfigStrings.Message)
 			viper.Set("Filename", ClaroConfigStrings.Filename)
 			viper.Set("Grade", ClaroConfigStrings.Grade)
+			viper.Set("Delivery", ClaroConfigStrings.Delivery)
+			viper.Set("Label", ClaroConfigStrings.Label)
+			viper.Set("Rubric", ClaroConfigStrings.Rubric)
+			viper.Set("Host", ClaroConfigStrings.Host)
+			viper.Set("CloneRoot", ClaroConfigStrings.CloneRoot)
+			viper.Set("Language", ClaroConfigStrings.Language)
 			if err := viper.WriteConfig(); err != nil {
 				_, _ = fmt.Fprintln(os.Stderr, err)
 			}
@@ -132,3 +252,223 @@ func ConfigCmd(cmd *cobra.Command, args []string) error {
 		}
 	}
 }
+
+// DeliveryUsesAPI reports whether the configured feedback delivery requires the GitHub REST API
+func DeliveryUsesAPI() bool {
+	return viper.GetString("delivery") != deliveryCommit
+}
+
+var errVersionManaged = errors.New("the configuration version is managed by claro")
+
+// LoadAssignmentConfig layers the per-assignment config files over the global configuration. The
+// course's config file (in the parent of the submissions directory) is read first, so the assignment's
+// config file takes precedence over it. Environment variables still take precedence over both.
+func LoadAssignmentConfig(submissionsDirectory string) error {
+	directory, _ := filepath.Abs(expandHomeDirectory(submissionsDirectory))
+	for _, dir := range []string{filepath.Dir(directory), directory} {
+		path := filepath.Join(dir, assignmentConfigFilename)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		v := viper.New()
+		v.SetConfigFile(path)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("unable to read %s: %w", path, err)
+		}
+		for _, key := range v.AllKeys() {
+			if err := checkConfigKey(key); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if key == "version" {
+				return fmt.Errorf("%s: %w", path, errVersionManaged)
+			}
+		}
+		// A relative rubric path is relative to the config file that sets it
+		if r := v.GetString("rubric"); r != "" && !filepath.IsAbs(r) {
+			v.Set("rubric", filepath.Join(dir, r))
+		}
+		if err := viper.MergeConfigMap(v.AllSettings()); err != nil {
+			return fmt.Errorf("unable to merge %s: %w", path, err)
+		}
+	}
+	return viper.Unmarshal(ClaroConfigStrings)
+}
+
+// configKeys returns the configuration keys, as declared in ClaroCfg
+func configKeys() []string {
+	var keys []string
+	t := reflect.TypeOf(ClaroCfg{})
+	for i := 0; i < t.NumField(); i++ {
+		if k := t.Field(i).Tag.Get("mapstructure"); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// checkConfigKey returns an error if the key is not a claro configuration key
+func checkConfigKey(key string) error {
+	if !slices.Contains(configKeys(), key) {
+		return fmt.Errorf("unknown configuration key '%s'. Valid keys are: %s", key, strings.Join(configKeys(), ", "))
+	}
+	return nil
+}
+
+// checkConfigValue returns an error if the value can't be set to the key
+func checkConfigValue(key string, value string) error {
+	switch key {
+	case "version":
+		return errVersionManaged
+	case "delivery":
+		modes := []string{deliveryCommit, deliveryReview, deliveryComment, deliveryIssue}
+		if !slices.Contains(modes, value) {
+			return fmt.Errorf("invalid delivery mode '%s'. Valid modes are: %s", value, strings.Join(modes, ", "))
+		}
+	case "language":
+		if value != "" && !slices.Contains(i18n.Languages, value) {
+			return fmt.Errorf("invalid language '%s'. Valid languages are: %s", value, strings.Join(i18n.Languages, ", "))
+		}
+	case "message":
+		return checkMessageTemplate(value)
+	case "authoremail":
+		if value != "" && !strings.Contains(value, "@") {
+			return fmt.Errorf("invalid email '%s'", value)
+		}
+	case "signing":
+		if value != "" && value != signingGPG && value != signingSSH {
+			return fmt.Errorf("invalid signing format '%s'. Valid formats are: %s, %s or empty to follow your git config", value, signingGPG, signingSSH)
+		}
+	case "gradetag":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value '%s' for gradetag. Valid values are: true, false", value)
+		}
+	case "grader":
+		if value != "" && !graderPattern.MatchString(value) {
+			return fmt.Errorf("invalid grader '%s'. The grader is written as 'Name <email>'", value)
+		}
+	}
+	return nil
+}
+
+// graderPattern matches a grader written as in a Co-authored-by trailer: Name <email>
+var graderPattern = regexp.MustCompile(`^[^<>]*[^<>\s] <[^<>\s]+@[^<>\s]+>$`)
+
+// SetGrader sets the grader named in the grading commits, given with the --grader flag
+func SetGrader(grader string) error {
+	if err := checkConfigValue("grader", grader); err != nil {
+		return err
+	}
+	viper.Set("grader", grader)
+	return nil
+}
+
+// configFilePath returns the path of the config file in use
+func configFilePath() string {
+	if f := viper.ConfigFileUsed(); f != "" {
+		return f
+	}
+	return FindConfigFile(ConfigDir(), "config")
+}
+
+// readConfigFile returns only the settings stored in the config file, without defaults and environment variables
+func readConfigFile() (*viper.Viper, error) {
+	v := viper.New()
+	v.SetConfigFile(configFilePath())
+	if err := v.ReadInConfig(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return v, nil
+}
+
+// writeConfigFile replaces the settings stored in the config file
+func writeConfigFile(settings map[string]any) error {
+	v := viper.New()
+	v.SetConfigFile(configFilePath())
+	for k, value := range settings {
+		v.Set(k, value)
+	}
+	if err := os.MkdirAll(filepath.Dir(configFilePath()), 0755); err != nil {
+		return err
+	}
+	return v.WriteConfig()
+}
+
+// configSource returns where the value of a key comes from: an environment variable, the config file or claro's defaults
+func configSource(file *viper.Viper, key string) string {
+	if _, ok := os.LookupEnv(EnvPrefix + "_" + strings.ToUpper(key)); ok {
+		return "env"
+	}
+	if file.IsSet(key) {
+		return "file"
+	}
+	return "default"
+}
+
+// ConfigGetCmd prints the value of a configuration key
+func ConfigGetCmd(cmd *cobra.Command, args []string) error {
+	if err := checkConfigKey(args[0]); err != nil {
+		return err
+	}
+	fmt.Println(viper.GetString(args[0]))
+	return nil
+}
+
+// ConfigSetCmd stores the value of a configuration key in the config file
+func ConfigSetCmd(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+	if err := checkConfigKey(key); err != nil {
+		return err
+	}
+	if err := checkConfigValue(key, value); err != nil {
+		return err
+	}
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	settings := file.AllSettings()
+	settings[key] = value
+	return writeConfigFile(settings)
+}
+
+// ConfigUnsetCmd removes a configuration key from the config file, so its default value is used
+func ConfigUnsetCmd(cmd *cobra.Command, args []string) error {
+	if err := checkConfigKey(args[0]); err != nil {
+		return err
+	}
+	if args[0] == "version" {
+		return errVersionManaged
+	}
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	settings := file.AllSettings()
+	delete(settings, args[0])
+	return writeConfigFile(settings)
+}
+
+// ConfigListCmd prints all configuration keys, their values and where each value comes from
+func ConfigListCmd(cmd *cobra.Command, args []string) error {
+	file, err := readConfigFile()
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, key := range configKeys() {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t(%s)\n", key, viper.GetString(key), configSource(file, key))
+	}
+	return w.Flush()
+}
+
+// ConfigEditCmd opens the config file in the user's editor
+func ConfigEditCmd(cmd *cobra.Command, args []string) error {
+	if _, err := os.Stat(configFilePath()); os.IsNotExist(err) {
+		if err = viper.SafeWriteConfigAs(configFilePath()); err != nil {
+			return err
+		}
+	}
+	editor := editorCommand(configFilePath())
+	editor.Stdin, editor.Stdout, editor.Stderr = os.Stdin, os.Stdout, os.Stderr
+	return editor.Run()
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheckConfigValue(t *testing.T) {
+	tests := []struct {
+		key, value string
+		valid      bool
+	}{
+		{"delivery", "commit", true},
+		{"delivery", "review", true},
+		{"delivery", "comment", true},
+		{"delivery", "issue", true},
+		{"delivery", "", false},
+		{"delivery", "Review", false},
+		{"delivery", "email", false},
+		{"language", "", true},
+		{"language", "en", true},
+		{"language", "pt-BR", true},
+		{"language", "es", true},
+		{"language", "pt", false},
+		{"language", "pt_br", false},
+		{"message", "{{.Assignment}} graded: {{.Grade}}", true},
+		{"message", "{{.Score}}", false},
+		{"authoremail", "", true},
+		{"authoremail", "ana@example.edu", true},
+		{"authoremail", "ana", false},
+		{"signing", "", true},
+		{"signing", "gpg", true},
+		{"signing", "ssh", true},
+		{"signing", "x509", false},
+		{"gradetag", "true", true},
+		{"gradetag", "false", true},
+		{"gradetag", "1", true},
+		{"gradetag", "", false},
+		{"gradetag", "yes", false},
+		{"grader", "", true},
+		{"grader", "Ana Silva <ana@example.edu>", true},
+		{"grader", "Ana <ana@example.edu>", true},
+		{"grader", "Ana Silva", false},
+		{"grader", "<ana@example.edu>", false},
+		{"grader", "Ana Silva <ana>", false},
+		{"grader", "Ana Silva <ana@example.edu> extra", false},
+		{"grade", "anything goes", true},
+		{"cloneroot", "", true},
+	}
+	for _, tt := range tests {
+		if err := checkConfigValue(tt.key, tt.value); (err == nil) != tt.valid {
+			t.Errorf("checkConfigValue(%q, %q) = %v, want valid %v", tt.key, tt.value, err, tt.valid)
+		}
+	}
+
+	if err := checkConfigValue("version", "3"); !errors.Is(err, errVersionManaged) {
+		t.Errorf("checkConfigValue(version) = %v, want %v", err, errVersionManaged)
+	}
+}
+
+func TestSetGrader(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("grader", "Ana Silva <ana@example.edu>")
+
+	if err := SetGrader("Bruno Souza"); err == nil {
+		t.Error("SetGrader accepted a grader without an email")
+	}
+	if got := viper.GetString("grader"); got != "Ana Silva <ana@example.edu>" {
+		t.Errorf("grader = %q after an invalid grader, want it unchanged", got)
+	}
+	if err := SetGrader("Bruno Souza <bruno@example.edu>"); err != nil {
+		t.Fatal(err)
+	}
+	if got := viper.GetString("grader"); got != "Bruno Souza <bruno@example.edu>" {
+		t.Errorf("grader = %q", got)
+	}
+}
+
+func TestConfigSource(t *testing.T) {
+	file := viper.New()
+	file.Set("filename", "feedback.md")
+	t.Setenv("LANGUAGE", "de")
+	t.Setenv("HOST", "example.com")
+	t.Setenv(EnvPrefix+"_SIGNING", "ssh")
+	tests := map[string]string{
+		"signing":  "env",
+		"filename": "file",
+		"language": "default",
+		"host":     "default",
+	}
+	for key, want := range tests {
+		if got := configSource(file, key); got != want {
+			t.Errorf("configSource(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+const actionContributions = "contributions"
+
+// noreplyEmailPattern matches the email GitHub gives to users who keep theirs private,
+// 12345+login@users.noreply.github.com or login@users.noreply.github.com
+var noreplyEmailPattern = regexp.MustCompile(`(?i)^(?:\d+\+)?([a-z0-9][a-z0-9-]*)@users\.noreply\.github\.com$`)
+
+// contribution is what an author contributed to a repository. Authors mapped to a team member are
+// identified by their GitHub login.
+type contribution struct {
+	Login   string
+	Author  string
+	Commits int
+	Added   int
+	Removed int
+}
+
+// name returns how the author is shown in the contribution report
+func (c contribution) name() string {
+	if c.Login != "" {
+		return "@" + c.Login
+	}
+	return c.Author
+}
+
+// authorLogin returns the GitHub login of the team member who authored a commit, or "" if the author
+// can't be mapped to a member. Authors are mapped by their GitHub noreply email, or by a name or an
+// email user that matches a member's login.
+func authorLogin(name string, email string, members []string) string {
+	candidates := []string{name}
+	if match := noreplyEmailPattern.FindStringSubmatch(email); match != nil {
+		candidates = append([]string{match[1]}, candidates...)
+	}
+	if user, _, ok := strings.Cut(email, "@"); ok {
+		candidates = append(candidates, user)
+	}
+	for _, candidate := range candidates {
+		for _, member := range members {
+			if strings.EqualFold(candidate, member) {
+				return member
+			}
+		}
+	}
+	return ""
+}
+
+// repositoryContributions returns the commits and the lines added and removed by each author of the
+// repository, leaving out merges and the grading file. Every team member is listed, even those without
+// commits, followed by the authors that couldn't be mapped to a member.
+func repositoryContributions(directory string, members []string) ([]contribution, error) {
+	out, err := gitRunner.Output(directory, "log", "--no-merges", "--numstat", "--format=%x00%an%x09%ae", "HEAD",
+		"--", ".", ":(exclude)"+viper.GetString("filename"))
+	if err != nil {
+		return nil, err
+	}
+	contributions := make([]contribution, 0, len(members))
+	for _, member := range members {
+		contributions = append(contributions, contribution{Login: member})
+	}
+	for _, commit := range strings.Split(string(out), "\x00")[1:] {
+		lines := strings.Split(strings.TrimSpace(commit), "\n")
+		name, email, _ := strings.Cut(lines[0], "\t")
+		login := authorLogin(name, email, members)
+		author := fmt.Sprintf("%s <%s>", name, email)
+		i := slices.IndexFunc(contributions, func(c contribution) bool {
+			return login != "" && c.Login == login || login == "" && c.Login == "" && c.Author == author
+		})
+		if i < 0 {
+			contributions = append(contributions, contribution{Author: author})
+			i = len(contributions) - 1
+		}
+		contributions[i].Commits++
+		for _, line := range lines[1:] {
+			// Binary files are listed as "-\t-\tpath"
+			fields := strings.Fields(line)
+			if len(fields) < 3 {
+				continue
+			}
+			added, _ := strconv.Atoi(fields[0])
+			removed, _ := strconv.Atoi(fields[1])
+			contributions[i].Added += added
+			contributions[i].Removed += removed
+		}
+	}
+	return contributions, nil
+}
+
+// contributionsSection returns the contribution report as a Markdown section that graders can include
+// in the feedback
+func contributionsSection(contributions []contribution) string {
+	var b strings.Builder
+	b.WriteString("## " + i18n.T("contributions.heading") + "\n\n")
+	b.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", i18n.T("contributions.author"), i18n.T("contributions.commits"),
+		i18n.T("contributions.added"), i18n.T("contributions.removed")))
+	b.WriteString("|---|---:|---:|---:|\n")
+	for _, c := range contributions {
+		b.WriteString(fmt.Sprintf("| %s | %d | %d | %d |\n", strings.ReplaceAll(c.name(), "|", "\\|"), c.Commits, c.Added, c.Removed))
+	}
+	return b.String()
+}
+
+// replaceSection replaces the section of the Markdown document starting with the heading, up to the
+// next heading of the same level, or appends the section if the document has none
+func replaceSection(document string, heading string, section string) string {
+	lines := strings.Split(document, "\n")
+	start := slices.Index(lines, heading)
+	if start < 0 && strings.TrimSpace(document) == "" {
+		return section
+	} else if start < 0 {
+		return strings.TrimRight(document, "\n") + "\n\n" + section
+	}
+	end := len(lines)
+	for i := start + 1; i < len(lines); i++ {
+		if strings.HasPrefix(lines[i], "## ") {
+			end = i
+			break
+		}
+	}
+	rest := strings.Join(lines[end:], "\n")
+	if rest != "" {
+		section += "\n"
+	}
+	before := ""
+	if start > 0 {
+		before = strings.Join(lines[:start], "\n") + "\n"
+	}
+	return before + section + rest
+}
+
+// gitContributions writes the contribution report of the repository in the directory to
+// contributions-<repository>.md, in the submissions directory. With appendToGradeFile, the report is
+// also written to the repository's grade file, replacing the report written before.
+func gitContributions(directory string, appendToGradeFile bool) step {
+	result := repositoryResult(actionContributions, directory)
+	return newStep(gitOperation{}, func(error) Result {
+		m, _ := loadManifest(filepath.Dir(directory))
+		var members []string
+		if entry, ok := m.repository(result.Repository); ok {
+			members = entry.Students
+		} else if result.Student != "" {
+			members = strings.Split(result.Student, ",")
+		}
+		contributions, err := repositoryContributions(directory, members)
+		if err != nil {
+			return result.failed(i18n.T("contributions.failed", err))
+		}
+		section := contributionsSection(contributions)
+		reportFilename := filepath.Join(filepath.Dir(directory), "contributions-"+result.Repository+".md")
+		if err = os.WriteFile(reportFilename, []byte(section), 0644); err != nil {
+			return result.failed(i18n.T("contributions.writeError", err))
+		}
+		if appendToGradeFile {
+			gradeFile := filepath.Join(filepath.Dir(directory), gradeFilename(result.Repository))
+			content, err := os.ReadFile(gradeFile)
+			if err == nil {
+				heading := "## " + i18n.T("contributions.heading")
+				err = os.WriteFile(gradeFile, []byte(replaceSection(string(content), heading, section)), 0644)
+			}
+			if err != nil {
+				return result.failed(i18n.T("contributions.writeError", err))
+			}
+		}
+		commits := 0
+		for _, c := range contributions {
+			commits += c.Commits
+		}
+		return result.succeeded(i18n.T("contributions.authors", len(contributions), commits))
+	})
+}
+
+// RunContributions writes the contribution report of each student's repository in the submissions
+// directory
+func RunContributions(directory string, appendToGradeFile bool, r Reporter) error {
+	directory, _ = filepath.Abs(expandHomeDirectory(directory))
+	s, err := scanSubmissions(directory)
+	if err != nil {
+		return err
+	}
+	reportSkipped(r, s.skipped)
+	if len(s.repositories) == 0 {
+		return errors.New(i18n.T("dir.noRepositories", directory))
+	}
+	r.Progress(i18n.T("contributions.writing", len(s.repositories)))
+	var summary Summary
+	for _, entry := range s.repositories {
+		summary.add(reportResult(r, gitContributions(filepath.Join(directory, entry.Name()), appendToGradeFile).run()))
+	}
+	r.Done(i18n.T("contributions.done", summary.count(StatusSucceeded)), summary)
+	return summary.ExitError(false)
+}
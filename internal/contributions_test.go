@@ -0,0 +1,77 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAuthorLogin(t *testing.T) {
+	members := []string{"alice", "Bob-Dev"}
+	tests := []struct {
+		name, email string
+		want        string
+	}{
+		{"Alice Lima", "12345+alice@users.noreply.github.com", "alice"},
+		{"Alice Lima", "alice@users.noreply.github.com", "alice"},
+		{"Alice Lima", "ALICE@USERS.NOREPLY.GITHUB.COM", "alice"},
+		{"bob-dev", "bob@example.edu", "Bob-Dev"},
+		{"Bob", "bob-dev@example.edu", "Bob-Dev"},
+		{"Alice Lima", "alice.lima@example.edu", ""},
+		{"alice", "12345+carol@users.noreply.github.com", "alice"},
+		{"Carol", "12345+carol@users.noreply.github.com", ""},
+		{"Carol", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := authorLogin(tt.name, tt.email, members); got != tt.want {
+			t.Errorf("authorLogin(%q, %q) = %q, want %q", tt.name, tt.email, got, tt.want)
+		}
+	}
+	if got := authorLogin("alice", "alice@example.edu", nil); got != "" {
+		t.Errorf("authorLogin without members = %q", got)
+	}
+}
+
+func TestReplaceSection(t *testing.T) {
+	const heading, section = "## Contributions", "## Contributions\n\nnew\n"
+	tests := map[string]struct {
+		document, want string
+	}{
+		"no section":           {"# Feedback\n\n- **Grade: 8**\n", "# Feedback\n\n- **Grade: 8**\n\n## Contributions\n\nnew\n"},
+		"no trailing line":     {"# Feedback", "# Feedback\n\n## Contributions\n\nnew\n"},
+		"last section":         {"# Feedback\n\n## Contributions\n\nold\n", "# Feedback\n\n## Contributions\n\nnew\n"},
+		"before another":       {"# Feedback\n\n## Contributions\n\nold\n\n## Members\n\n- **@alice: **\n", "# Feedback\n\n## Contributions\n\nnew\n\n## Members\n\n- **@alice: **\n"},
+		"replaces subsections": {"## Contributions\n\nold\n### Notes\nmore\n## Members\n", "## Contributions\n\nnew\n\n## Members\n"},
+		"first line":           {"## Contributions\n\nold\n", "## Contributions\n\nnew\n"},
+		"heading in a line":    {"# Feedback\n\nSee ## Contributions below\n", "# Feedback\n\nSee ## Contributions below\n\n## Contributions\n\nnew\n"},
+		"empty document":       {"", "## Contributions\n\nnew\n"},
+		"replaced only once":   {"## Contributions\nold\n## Contributions\nolder\n", "## Contributions\n\nnew\n\n## Contributions\nolder\n"},
+	}
+	for name, tt := range tests {
+		if got := replaceSection(tt.document, heading, section); got != tt.want {
+			t.Errorf("%s: replaceSection() = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestRepositoryContributions(t *testing.T) {
+	git := newFakeGitRunner()
+	git.on("log", fakeGitOutcome{stdout: "\x00Alice Lima\t1+alice@users.noreply.github.com\n\n10\t2\tmain.c\n3\t0\tREADME.md\n" +
+		"\x00Carol\tcarol@example.edu\n\n1\t1\tmain.c\n" +
+		"\x00alice\talice@example.edu\n\n-\t-\tlogo.png\n5\t5\tmain.c\n" +
+		"\x00Carol\tcarol@example.edu\n"})
+	git.use(t)
+
+	got, err := repositoryContributions(t.TempDir(), []string{"alice", "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []contribution{
+		{Login: "alice", Commits: 2, Added: 18, Removed: 7},
+		{Login: "bob"},
+		{Author: "Carol <carol@example.edu>", Commits: 2, Added: 1, Removed: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("repositoryContributions() = %+v, want %+v", got, want)
+	}
+}
@@ -6,21 +6,36 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 
 	"github.com/charmbracelet/huh"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/emersonmello/claro/internal/tui"
+	"github.com/spf13/viper"
 	"github.com/zalando/go-keyring"
+	"golang.org/x/term"
 )
 
 const service = "a github classroom cli"
-const user = "claro"
+
+// tokenEnvVars are the environment variables the GitHub Personal Access Token is read from, in order of
+// precedence, so scripts and CI jobs run without the OS keyring or a terminal
+var tokenEnvVars = []string{"CLARO_TOKEN", "GITHUB_TOKEN"}
+
+// keyringUser returns the OS keyring entry of the GitHub Personal Access Token, which is specific to each profile
+func keyringUser() string {
+	if u := viper.GetString("keyring"); u != "" {
+		return u
+	}
+	return configFilename
+}
 
 // DeletePasswordItem Delete the user's GitHub personal access token from the operating system keyring
 func deletePasswordItem() error {
-	return keyring.Delete(service, user)
+	return keyring.Delete(service, keyringUser())
 }
 
 // CreateKey Store the user's GitHub personal access token in the operating system keyring
@@ -28,35 +43,38 @@ func createKey(password string, removeIfExist bool) error {
 	if removeIfExist {
 		_ = deletePasswordItem()
 	}
-	e := keyring.Set(service, user, password)
+	e := keyring.Set(service, keyringUser(), password)
 	if e != nil {
-		fmt.Println(tui.ErrorStyle.Render(fmt.Sprintf("Could not store token in operating system keyring:\n => %s", e)))
+		fmt.Println(tui.ErrorStyle.Render(i18n.T("token.storeError", e)))
 	} else {
-		fmt.Println(tui.DoneStyle.Render("Your github personal access token has been successfully set in the operating system keyring!"))
+		fmt.Println(tui.DoneStyle.Render(i18n.T("token.stored")))
 	}
 	return e
 }
 
 // GetPassword Retrieve the user's GitHub personal access token from the operating system keyring
 func getPassword() (string, error) {
-	return keyring.Get(service, user)
+	return keyring.Get(service, keyringUser())
 }
 
-// ReadTokenFromStdIn To obtain the user's GitHub Personal Access Token
-func readTokenFromStdIn() string {
+// ReadTokenFromStdIn To obtain the user's GitHub Personal Access Token. Without a terminal, nobody can
+// answer the prompt, so an error is returned instead.
+func readTokenFromStdIn() (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", errors.New(i18n.T("token.noTerminal", strings.Join(tokenEnvVars, " or ")))
+	}
 	var userToken string
 	group := huh.NewGroup(
 		huh.NewInput().
 			Value(&userToken).Placeholder("Ex: ghp_1873SsDhdjf....").
-			Title("Provide your GitHub Personal Access Token (classic):").
+			Title(i18n.T("token.prompt")).
 			EchoMode(huh.EchoModePassword),
 	)
 	form := huh.NewForm(group)
 	if err := form.Run(); err != nil {
-		_, _ = fmt.Fprintln(os.Stderr, err)
-		os.Exit(0)
+		return "", err
 	}
-	return userToken
+	return strings.TrimSpace(userToken), nil
 }
 
 // yesNoDialog displays a yes/no confirmation dialog with the given message.
@@ -79,50 +97,63 @@ func yesNoDialog(msg string) bool {
 // DeleteTokenFromKeyring deletes the GitHub Personal Access Token from the OS keyring.
 func DeleteTokenFromKeyring() {
 	if ghToken, _ := getPassword(); ghToken != "" {
-		confirm := yesNoDialog("Are you sure you want to delete the GitHub Personal Access Token from the operating system keyring?")
+		confirm := yesNoDialog(i18n.T("token.confirmDelete"))
 		if confirm {
 			if err := deletePasswordItem(); err != nil {
 				if strings.Contains(err.Error(), "secret not found") {
-					fmt.Println(tui.ErrorStyle.Render("Secret not found in OS Keychain."))
+					fmt.Println(tui.ErrorStyle.Render(i18n.T("token.secretNotFound")))
 				} else {
-					fmt.Println(tui.ErrorStyle.Render(fmt.Sprintf("Error deleting token from OS Keychain: %s", err)))
+					fmt.Println(tui.ErrorStyle.Render(i18n.T("token.deleteError", err)))
 				}
 			} else {
-				fmt.Println(tui.DoneStyle.Render("Token deleted from OS Keychain"))
+				fmt.Println(tui.DoneStyle.Render(i18n.T("token.deleted")))
 			}
 		}
 	} else {
-		fmt.Println(tui.ErrorStyle.Render("No token found in OS Keychain. Nothing to delete."))
+		fmt.Println(tui.ErrorStyle.Render(i18n.T("token.nothingToDelete")))
 	}
 }
 
 // AddTokenToKeyring adds a GitHub Personal Access Token to the OS keyring.
-func AddTokenToKeyring() {
+func AddTokenToKeyring() error {
 	if ghToken, _ := getPassword(); ghToken != "" {
-		confirm := yesNoDialog("A GitHub Personal Access Token for claro is already stored in the operating system keyring. Would you like to override it?")
+		confirm := yesNoDialog(i18n.T("token.confirmOverride"))
 		if !confirm {
-			return
+			return nil
 		}
 	}
-	if ghToken := readTokenFromStdIn(); ghToken != "" {
+	ghToken, err := readTokenFromStdIn()
+	if errors.Is(err, huh.ErrUserAborted) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if ghToken != "" {
 		_ = createKey(ghToken, true)
 	}
+	return nil
 }
 
-// GetAndSaveToken retrieves the GitHub Personal Access Token from the OS keyring.
-// Returns the GitHub Personal Access Token.
-func GetAndSaveToken() string {
-	var ghToken string
-	if ghToken, _ = getPassword(); ghToken == "" {
-		if ghToken = readTokenFromStdIn(); ghToken != "" {
-			// If the token is not found, it prompts the user to input the token and optionally saves it in the OS keyring.
-			//persist := yesNoDialog("Would you like to save this token in the OS keyring?")
-			//if persist {
-			//	_ = createKey(ghToken, true)
-			//}
+// GetAndSaveToken retrieves the GitHub Personal Access Token from the CLARO_TOKEN or GITHUB_TOKEN
+// environment variables, or from the OS keyring, prompting for it when it's in neither.
+// Returns the GitHub Personal Access Token, or an error if none was given.
+func GetAndSaveToken() (string, error) {
+	for _, name := range tokenEnvVars {
+		if ghToken := strings.TrimSpace(os.Getenv(name)); ghToken != "" {
+			return ghToken, nil
 		}
 	}
-	return ghToken
+	if ghToken, _ := getPassword(); ghToken != "" {
+		return ghToken, nil
+	}
+	ghToken, err := readTokenFromStdIn()
+	if err != nil {
+		return "", err
+	}
+	if ghToken == "" {
+		return "", errors.New(i18n.T("token.missing"))
+	}
+	return ghToken, nil
 }
 
 // writeConfigFile key/value in the config file
@@ -0,0 +1,26 @@
+package internal
+
+import "testing"
+
+func TestGetAndSaveTokenFromEnvironment(t *testing.T) {
+	tests := map[string]struct {
+		claro, github string
+		want          string
+	}{
+		"claro token":         {claro: "claro-token", want: "claro-token"},
+		"github token":        {github: "github-token", want: "github-token"},
+		"claro token first":   {claro: "claro-token", github: "github-token", want: "claro-token"},
+		"surrounding spaces":  {github: " github-token\n", want: "github-token"},
+		"blank claro ignored": {claro: "  ", github: "github-token", want: "github-token"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("CLARO_TOKEN", tt.claro)
+			t.Setenv("GITHUB_TOKEN", tt.github)
+			got, err := GetAndSaveToken()
+			if err != nil || got != tt.want {
+				t.Errorf("GetAndSaveToken() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+)
+
+type stateDiff int
+
+const (
+	initialDiff stateDiff = iota
+	diffDir
+)
+
+// DiffModel represents the model for the diff command
+type DiffModel struct {
+	state                stateDiff
+	submissionsDirectory string
+	starterRepository    string
+	starterPath          string
+	repositories         []os.DirEntry
+	totalDiffed          int
+	index                int
+	progress             progress.Model
+	done                 bool
+	width                int
+	height               int
+	summary              Summary
+}
+
+// NewDiffModel creates a new DiffModel. If starterRepository is empty, the starter code repository
+// recorded by the clone command in the submissions directory is used.
+func NewDiffModel(directory string, starterRepository string) DiffModel {
+	p := progress.New(
+		progress.WithDefaultGradient(),
+		progress.WithWidth(50),
+		progress.WithoutPercentage(),
+	)
+	directory, _ = filepath.Abs(expandHomeDirectory(directory))
+	return DiffModel{
+		state:                initialDiff,
+		submissionsDirectory: directory,
+		starterRepository:    starterRepository,
+		starterPath:          filepath.Join(stateDir(directory), "starter"),
+		progress:             p,
+	}
+}
+
+func (m DiffModel) Init() tea.Cmd {
+	return findStarterRepository(m.submissionsDirectory, m.starterRepository)
+}
+
+func (m DiffModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "esc", "q":
+			return m, tea.Quit
+		}
+	}
+	switch m.state {
+	case initialDiff:
+		return initialDiffUpdate(msg, m)
+	case diffDir:
+		return diffUpdate(msg, m)
+	}
+	return m, nil
+}
+
+func initialDiffUpdate(msg tea.Msg, m DiffModel) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tui.AssignmentDirError:
+		m.summary.Err = errors.New(string(msg))
+		return m, tea.Sequence(tea.Printf("%s", tui.ErrorStyle.Render(string(msg)+"\n")), tea.Quit)
+	case tui.StarterRepositoryMsg:
+		return m, tea.Sequence(tea.Printf("%s\n", i18n.T("diff.fetchingStarter", msg)), gitPrepareStarterRepository(string(msg), m.starterPath).cmd())
+	case Result:
+		// The starter code repository is ready
+		if msg.Status != StatusSucceeded {
+			m.summary.Err = errors.New(msg.Error)
+			return m, tea.Sequence(tea.Printf("%s", tui.ErrorStyle.Render(msg.Error+"\n")), tea.Quit)
+		}
+		return m, getReposDirectoryList(m.submissionsDirectory)
+	case submissions:
+		m.repositories = msg.repositories
+		if len(m.repositories) > 0 {
+			m.state = diffDir
+			m.index = 0
+			return m, tea.Sequence(skippedLines(msg.skipped), tea.Printf("%s\n", i18n.T("diff.comparing", len(m.repositories))), m.diffCurrent())
+		}
+		m.summary.Err = errors.New(i18n.T("dir.noRepositories", m.submissionsDirectory))
+		return m, tea.Sequence(skippedLines(msg.skipped), tea.Printf("%s", tui.ErrorStyle.Render(m.summary.Err.Error()+"\n")), tea.Quit)
+	}
+	return m, nil
+}
+
+func diffUpdate(msg tea.Msg, m DiffModel) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch msg := msg.(type) {
+	case Result:
+		if msg.Status == StatusSucceeded {
+			m.totalDiffed++
+		}
+		m.summary.add(msg)
+		cmd = resultLine(msg)
+	case progress.FrameMsg:
+		newModel, cmd := m.progress.Update(msg)
+		if newModel, ok := newModel.(progress.Model); ok {
+			m.progress = newModel
+		}
+		return m, cmd
+	default:
+		return m, nil
+	}
+	if m.index >= len(m.repositories)-1 {
+		m.done = true
+		return m, tea.Sequence(cmd, tea.Quit)
+	}
+	m.index++
+	return m, tea.Sequence(cmd, m.diffCurrent())
+}
+
+// Summary returns the results of the compared repositories, or the error that stopped the command
+func (m DiffModel) Summary() Summary {
+	return m.summary
+}
+
+// diffCurrent returns the command that compares the current repository with the starter code
+func (m DiffModel) diffCurrent() tea.Cmd {
+	name := m.repositories[m.index].Name()
+	fullpath := filepath.Join(m.submissionsDirectory, name)
+	patchFilename := filepath.Join(m.submissionsDirectory, "diff-"+name+".patch")
+	return gitDiffAgainstStarter(m.starterPath, fullpath, patchFilename).cmd()
+}
+
+func (m DiffModel) View() string {
+	if m.state != diffDir {
+		return ""
+	}
+	if m.done {
+		return tui.DoneStyle.Render(i18n.T("diff.done", m.totalDiffed) + "\n")
+	}
+	n := len(m.repositories)
+	w := lipgloss.Width(fmt.Sprintf("%d", n))
+	count := fmt.Sprintf(" %*d/%*d", w, m.index+1, w, n)
+	per := progressPercent(m.index, n)
+	prog := m.progress.ViewAs(per)
+
+	repository := tui.CurrentRepositoryStyle.Render(m.repositories[m.index].Name())
+	info := lipgloss.NewStyle().Render(fmt.Sprintf("%s %s ", tui.BowtieMark, repository))
+	newLine := lipgloss.NewStyle().Render("\n")
+	return info + newLine + "  " + prog + count + newLine
+}
+
+// findStarterRepository returns a tea.Cmd that finds the URL of the starter code repository, either the
+// given one or the one recorded in the submissions directory's manifest by the clone command
+func findStarterRepository(submissionsDirectory string, starterRepository string) tea.Cmd {
+	return func() tea.Msg {
+		url, err := starterRepositoryURL(submissionsDirectory, starterRepository)
+		if err != nil {
+			return tui.AssignmentDirError(err.Error())
+		}
+		return tui.StarterRepositoryMsg(url)
+	}
+}
+
+// starterRepositoryURL returns the URL of the starter code repository, either the given one or the one
+// recorded in the submissions directory's manifest by the clone command
+func starterRepositoryURL(submissionsDirectory string, starterRepository string) (string, error) {
+	if _, err := os.Stat(submissionsDirectory); os.IsNotExist(err) {
+		return "", errors.New(i18n.T("dir.notExist"))
+	}
+	if starterRepository != "" {
+		return starterRepository, nil
+	}
+	if m, err := loadManifest(submissionsDirectory); err == nil && m.Assignment.StarterCode != "" {
+		return m.Assignment.StarterCode, nil
+	}
+	return "", errors.New(i18n.T("diff.noStarter"))
+}
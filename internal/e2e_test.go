@@ -0,0 +1,1285 @@
+package internal
+
+// The end-to-end tests drive the clone, pull and push models, as the TUI does, against local bare
+// repositories standing for the students' repositories and an httptest server standing for the
+// GitHub Classroom API.
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/github/gh-classroom/pkg/classroom"
+	"github.com/spf13/viper"
+)
+
+const testAssignmentId = "42"
+
+// classroomFixture is an assignment whose students' repositories are local bare repositories
+type classroomFixture struct {
+	t          *testing.T
+	root       string
+	assignment classroom.Assignment
+	accepted   []classroom.AcceptedAssignment
+	// status, when set, is the status code of every answer of the Classroom API
+	status int
+}
+
+func newClassroomFixture(t *testing.T, students ...string) *classroomFixture {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root := t.TempDir()
+	t.Setenv("HOME", root)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(root, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_TERMINAL_PROMPT", "0")
+	for _, v := range []string{"GIT_AUTHOR", "GIT_COMMITTER"} {
+		t.Setenv(v+"_NAME", "Claro Test")
+		t.Setenv(v+"_EMAIL", "claro@example.com")
+	}
+
+	f := &classroomFixture{
+		t:          t,
+		root:       root,
+		assignment: classroom.Assignment{Id: 42, Slug: "hw", Title: "Homework"},
+	}
+	for _, student := range students {
+		f.accept(student)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(server.Close)
+	serverURL, _ := url.Parse(server.URL)
+	client, err := api.NewRESTClient(api.ClientOptions{Host: "github.com", AuthToken: "token", Transport: redirectTransport{serverURL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	previous := newClassroomAPI
+	newClassroomAPI = func() (ClassroomAPI, error) { return restClassroomAPI{client: client}, nil }
+	t.Cleanup(func() { newClassroomAPI = previous })
+
+	viper.Set("cloneroot", filepath.Join(root, "work"))
+	viper.Set("filename", "GRADING.md")
+	viper.Set("message", "Graded")
+	viper.Set("title", "Feedback")
+	viper.Set("grade", "Grade: ")
+	viper.Set("delivery", deliveryCommit)
+	viper.Set("rubric", "")
+	t.Cleanup(viper.Reset)
+	return f
+}
+
+// serve answers the GitHub Classroom API requests for the assignment
+func (f *classroomFixture) serve(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if f.status != 0 {
+		w.WriteHeader(f.status)
+		_ = json.NewEncoder(w).Encode(map[string]string{"message": http.StatusText(f.status)})
+		return
+	}
+	switch r.URL.Path {
+	case "/assignments/" + testAssignmentId:
+		_ = json.NewEncoder(w).Encode(f.assignment)
+	case "/assignments/" + testAssignmentId + "/accepted_assignments":
+		_ = json.NewEncoder(w).Encode(f.accepted)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// redirectTransport sends the requests to the GitHub REST API to the test server
+type redirectTransport struct {
+	server *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = t.server.Scheme, t.server.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// accept creates the student's repository, as GitHub Classroom does when they accept the assignment
+func (f *classroomFixture) accept(student string) {
+	f.t.Helper()
+	name := "hw-" + student
+	remote := f.remote(name)
+	runGit(f.t, "", "init", "-q", "--bare", "--initial-branch=main", remote)
+	f.commitToRemote(name, "README.md", "# "+name+"\n")
+	f.accepted = append(f.accepted, classroom.AcceptedAssignment{
+		Id:         len(f.accepted) + 1,
+		Students:   []classroom.Student{{Login: student}},
+		Repository: classroom.GithubRepository{Name: name, FullName: "classroom/" + name, HtmlUrl: remote},
+		Assignment: f.assignment,
+	})
+}
+
+func (f *classroomFixture) remote(name string) string {
+	return filepath.Join(f.root, "remotes", name+".git")
+}
+
+// submissions returns the submissions directory the repositories are cloned into
+func (f *classroomFixture) submissions() string {
+	return submissionsDirectory(f.assignment)
+}
+
+// commitToRemote commits a file to a student's remote repository, as the student would
+func (f *classroomFixture) commitToRemote(name string, file string, content string) {
+	f.t.Helper()
+	scratch := filepath.Join(f.t.TempDir(), name)
+	runGit(f.t, "", "clone", "-q", f.remote(name), scratch)
+	writeFile(f.t, filepath.Join(scratch, file), content)
+	runGit(f.t, scratch, "add", file)
+	runGit(f.t, scratch, "commit", "-q", "-m", "Add "+file)
+	runGit(f.t, scratch, "push", "-q", "origin", "HEAD:main")
+}
+
+// clone clones the students' repositories and fails the test unless all of them succeeded
+func (f *classroomFixture) clone() {
+	f.t.Helper()
+	s := runModel(f.t, NewCloneModel(testAssignmentId, false))
+	if s.Err != nil || s.count(StatusSucceeded) != len(f.accepted) {
+		f.t.Fatalf("clone: %v, %s", s.Err, s)
+	}
+}
+
+func runGit(t *testing.T, directory string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = directory
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return string(out)
+}
+
+func writeFile(t *testing.T, path string, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// runModel runs the model as the TUI does, without a terminal, and returns its summary
+func runModel(t *testing.T, m summarizer) Summary {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	p := tea.NewProgram(m, tea.WithContext(ctx), tea.WithInput(strings.NewReader("")), tea.WithOutput(io.Discard), tea.WithoutSignalHandler())
+	final, err := p.Run()
+	if err != nil {
+		t.Fatalf("running the model: %v", err)
+	}
+	return final.(summarizer).Summary()
+}
+
+// result returns the result of the repository in the summary
+func result(t *testing.T, s Summary, repository string) Result {
+	t.Helper()
+	for _, r := range s.Results {
+		if r.Repository == repository {
+			return r
+		}
+	}
+	t.Fatalf("no result for %s in %v", repository, s.Results)
+	return Result{}
+}
+
+func TestCloneModel(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	f.clone()
+
+	for _, name := range []string{"hw-alice", "hw-bob"} {
+		if _, err := os.Stat(filepath.Join(f.submissions(), name, "README.md")); err != nil {
+			t.Errorf("%s was not cloned: %v", name, err)
+		}
+		grade, err := os.ReadFile(filepath.Join(f.submissions(), "grade-"+name+".md"))
+		if err != nil || !strings.HasPrefix(string(grade), "# Feedback\n> Commit: ") {
+			t.Errorf("grade file of %s = %q, %v", name, grade, err)
+		}
+	}
+	m, err := loadManifest(f.submissions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := m.repository("hw-bob"); entry.FullName != "classroom/hw-bob" || len(entry.Students) != 1 || entry.Students[0] != "bob" {
+		t.Errorf("manifest entry of hw-bob = %+v", entry)
+	}
+	if j := openJournal(f.submissions()); !j.done(actionClone, "hw-alice") || !j.done(actionClone, "hw-bob") {
+		t.Errorf("journal = %+v, want both repositories done", j.Runs[actionClone])
+	}
+
+	// Cloning again skips the repositories already cloned
+	s := runModel(t, NewCloneModel(testAssignmentId, false))
+	if s.count(StatusSkipped) != 2 || s.ExitError(true) != nil {
+		t.Errorf("second clone: %s", s)
+	}
+}
+
+func TestCloneModelRepairsPartialClone(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	f.clone()
+	partial := filepath.Join(f.submissions(), "hw-bob")
+	if err := os.RemoveAll(partial); err != nil {
+		t.Fatal(err)
+	}
+	// A clone interrupted before checking out a commit
+	runGit(t, "", "init", "-q", partial)
+	j := openJournal(f.submissions())
+	if err := j.begin(actionClone, false); err != nil {
+		t.Fatal(err)
+	}
+	j.start(actionClone, "hw-bob")
+
+	s := runModel(t, NewCloneModel(testAssignmentId, false))
+	if r := result(t, s, "hw-bob"); r.Status != StatusSucceeded || r.Detail != "partial clone repaired" {
+		t.Errorf("hw-bob = %+v, want a repaired clone", r)
+	}
+	if r := result(t, s, "hw-alice"); r.Status != StatusSkipped {
+		t.Errorf("hw-alice = %+v, want skipped", r)
+	}
+	if _, err := os.Stat(filepath.Join(partial, "README.md")); err != nil {
+		t.Errorf("hw-bob was not cloned again: %v", err)
+	}
+}
+
+func TestCloneModelKeepsEmptyRepository(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	// bob accepted the assignment but the repository has no commits
+	if err := os.RemoveAll(f.remote("hw-bob")); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, "", "init", "-q", "--bare", "--initial-branch=main", f.remote("hw-bob"))
+	f.clone()
+	writeFile(t, filepath.Join(f.submissions(), "hw-bob", "notes.txt"), "grader's notes\n")
+
+	s := runModel(t, NewCloneModel(testAssignmentId, false))
+	if r := result(t, s, "hw-bob"); r.Status != StatusSkipped {
+		t.Errorf("hw-bob = %+v, want skipped", r)
+	}
+	if _, err := os.Stat(filepath.Join(f.submissions(), "hw-bob", "notes.txt")); err != nil {
+		t.Errorf("the clone of the empty repository was removed: %v", err)
+	}
+}
+
+func TestCloneModelResumeKeepsExistingClone(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	f.clone()
+	// A clone interrupted after visiting the existing clone, before recording it as skipped
+	j := openJournal(f.submissions())
+	if err := j.begin(actionClone, false); err != nil {
+		t.Fatal(err)
+	}
+	_ = j.clone(f.accepted[0])
+
+	s := runModel(t, NewCloneModel(testAssignmentId, true))
+	if r := result(t, s, "hw-alice"); r.Status != StatusSkipped {
+		t.Errorf("hw-alice = %+v, want skipped", r)
+	}
+	if _, err := os.Stat(filepath.Join(f.submissions(), "hw-alice", "README.md")); err != nil {
+		t.Errorf("the existing clone was removed: %v", err)
+	}
+}
+
+func TestCloneModelPartialFailure(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	if err := os.RemoveAll(f.remote("hw-bob")); err != nil {
+		t.Fatal(err)
+	}
+
+	s := runModel(t, NewCloneModel(testAssignmentId, false))
+	if r := result(t, s, "hw-bob"); r.Status != StatusFailed {
+		t.Errorf("hw-bob = %+v, want failed", r)
+	}
+	if code := ExitCode(s.ExitError(true)); code != ExitPartialFailure {
+		t.Errorf("exit code = %d, want %d", code, ExitPartialFailure)
+	}
+	if !openJournal(f.submissions()).done(actionClone, "hw-alice") || openJournal(f.submissions()).done(actionClone, "hw-bob") {
+		t.Errorf("journal = %+v, want only hw-alice done", openJournal(f.submissions()).Runs[actionClone])
+	}
+}
+
+func TestCloneModelBadCredentials(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	f.status = http.StatusUnauthorized
+
+	s := runModel(t, NewCloneModel(testAssignmentId, false))
+	if !isAuthError(s.Err) {
+		t.Errorf("error = %v, want an authentication error", s.Err)
+	}
+	if code := ExitCode(s.ExitError(true)); code != ExitAuthFailure {
+		t.Errorf("exit code = %d, want %d", code, ExitAuthFailure)
+	}
+}
+
+func TestPullModel(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	f.clone()
+	f.commitToRemote("hw-alice", "main.c", "int main(void) { return 0; }\n")
+
+	s := runModel(t, NewPullModel(f.submissions()))
+	if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded || r.Detail != "new commits" || r.Student != "alice" {
+		t.Errorf("hw-alice = %+v, want new commits", r)
+	}
+	if r := result(t, s, "hw-bob"); r.Status != StatusSucceeded || r.Detail != "" {
+		t.Errorf("hw-bob = %+v, want no new commits", r)
+	}
+	if _, err := os.Stat(filepath.Join(f.submissions(), "hw-alice", "main.c")); err != nil {
+		t.Errorf("the new commit was not pulled: %v", err)
+	}
+}
+
+func TestPushModel(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	f.clone()
+	for _, name := range []string{"hw-alice", "hw-bob"} {
+		writeFile(t, filepath.Join(f.submissions(), "grade-"+name+".md"), "# Feedback\n\n- **Grade: 9.5**\n")
+	}
+
+	s := runModel(t, NewPushModel(f.submissions(), false))
+	if s.count(StatusSucceeded) != 2 {
+		t.Fatalf("push: %s", s)
+	}
+	for _, name := range []string{"hw-alice", "hw-bob"} {
+		if grading := runGit(t, "", "--git-dir", f.remote(name), "show", "main:GRADING.md"); !strings.Contains(grading, "Grade: 9.5") {
+			t.Errorf("GRADING.md of %s = %q", name, grading)
+		}
+		if message := runGit(t, "", "--git-dir", f.remote(name), "log", "-1", "--format=%s", "main"); strings.TrimSpace(message) != "Graded" {
+			t.Errorf("commit message of %s = %q", name, message)
+		}
+	}
+}
+
+func TestPushIssueAfterReclone(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	viper.Set("delivery", deliveryIssue)
+	classroomAPI := newFakeClassroomAPI()
+	classroomAPI.assignment[testAssignmentId] = f.assignment
+	classroomAPI.accepted[testAssignmentId] = f.accepted
+	classroomAPI.use(t)
+
+	// Cloning again must keep the feedback issue recorded by the first push
+	for run := 1; run <= 2; run++ {
+		if s := runModel(t, NewCloneModel(testAssignmentId, false)); s.Err != nil || s.count(StatusFailed) != 0 {
+			t.Fatalf("clone %d: %v, %s", run, s.Err, s)
+		}
+		writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), fmt.Sprintf("# Feedback\n\n- **Grade: %d**\n", run))
+		s := runModel(t, NewPushModel(f.submissions(), false))
+		if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded {
+			t.Fatalf("push %d: hw-alice = %+v", run, r)
+		}
+	}
+	if issues := classroomAPI.issues["classroom/hw-alice"]; len(issues) != 1 || !strings.Contains(issues[0].Body, "Grade: 2") {
+		t.Errorf("issues = %+v, want one issue with the last grade", issues)
+	}
+}
+
+func TestPushIssueDeleted(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	f.clone()
+	viper.Set("delivery", deliveryIssue)
+	classroomAPI := newFakeClassroomAPI()
+	classroomAPI.use(t)
+	// The issue recorded by a previous push was deleted
+	m, err := loadManifest(f.submissions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _ := m.repository("hw-alice")
+	entry.Issue = 7
+	if err = saveManifest(f.submissions(), m.withRepository(entry)); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 8**\n")
+	s := runModel(t, NewPushModel(f.submissions(), false))
+	if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded || r.Detail != i18n.T("feedback.issueCreated", 1) {
+		t.Errorf("hw-alice = %+v, want a new issue", r)
+	}
+	m, _ = loadManifest(f.submissions())
+	if entry, _ = m.repository("hw-alice"); entry.Issue != 1 {
+		t.Errorf("recorded issue = %d, want 1", entry.Issue)
+	}
+}
+
+func TestPushModelResume(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	f.clone()
+	// A push interrupted after grading alice
+	j := openJournal(f.submissions())
+	if err := j.begin(actionPush, false); err != nil {
+		t.Fatal(err)
+	}
+	j.record(actionPush, Result{Repository: "hw-alice", Status: StatusSucceeded})
+	j.start(actionPush, "hw-bob")
+
+	s := runModel(t, NewPushModel(f.submissions(), true))
+	if len(s.Results) != 1 || result(t, s, "hw-bob").Status != StatusSucceeded {
+		t.Errorf("resumed push: %v, want only hw-bob", s.Results)
+	}
+	if log := runGit(t, "", "--git-dir", f.remote("hw-alice"), "log", "--format=%s", "main"); strings.Contains(log, "Graded") {
+		t.Errorf("hw-alice was graded again: %q", log)
+	}
+}
+
+func TestPushModelReview(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	f.clone()
+	writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 7**\n")
+	viper.Set("delivery", deliveryReview)
+	classroomAPI := newFakeClassroomAPI()
+	classroomAPI.use(t)
+
+	s := runModel(t, NewPushModel(f.submissions(), false))
+	if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded {
+		t.Fatalf("hw-alice = %+v", r)
+	}
+	if posted := classroomAPI.posted["classroom/hw-alice"]; len(posted) != 1 || !strings.Contains(posted[0], "Grade: 7") {
+		t.Errorf("posted feedback = %q", posted)
+	}
+	if log := runGit(t, "", "--git-dir", f.remote("hw-alice"), "log", "--format=%s", "main"); strings.Contains(log, "Graded") {
+		t.Errorf("the grading file was committed: %q", log)
+	}
+}
+
+func TestPushModelReviewAnnotations(t *testing.T) {
+	tests := []struct {
+		name           string
+		files          []pullRequestFile
+		rejectComments bool
+		inline         int
+		listed         []string
+	}{
+		{"inside the diff", []pullRequestFile{{"main.c", "@@ -0,0 +1,3 @@\n+int a;\n+int b;\n+int c;"}, {"README.md", "@@ -1 +1 @@\n-# hw\n+# hw-alice"}}, false, 2, nil},
+		{"outside the diff", []pullRequestFile{{"main.c", "@@ -0,0 +1,3 @@\n+int a;\n+int b;\n+int c;"}}, false, 1, []string{"**README.md:1**"}},
+		{"diff unavailable", nil, false, 0, []string{"**main.c:2**", "**README.md:1**"}},
+		{"inline comments rejected", []pullRequestFile{{"main.c", "@@ -0,0 +1,3 @@\n+int a;\n+int b;\n+int c;"}}, true, 0, []string{"**main.c:2**", "**README.md:1**"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newClassroomFixture(t, "alice")
+			f.commitToRemote("hw-alice", "main.c", "int a;\nint b;\nint c;\n")
+			f.clone()
+			writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 7**\n@main.c:2: unused\n@README.md:1: title\n")
+			viper.Set("delivery", deliveryReview)
+			classroomAPI := newFakeClassroomAPI()
+			classroomAPI.files["classroom/hw-alice"] = tt.files
+			classroomAPI.rejectComments = tt.rejectComments
+			classroomAPI.use(t)
+
+			s := runModel(t, NewPushModel(f.submissions(), false))
+			if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded {
+				t.Fatalf("hw-alice = %+v", r)
+			}
+			posted := classroomAPI.posted["classroom/hw-alice"]
+			if len(posted) != 1 || !strings.Contains(posted[0], "Grade: 7") {
+				t.Fatalf("posted feedback = %q", posted)
+			}
+			if inline := classroomAPI.reviewed["classroom/hw-alice"]; len(inline) != tt.inline {
+				t.Errorf("inline comments = %+v, want %d", inline, tt.inline)
+			}
+			for _, listed := range tt.listed {
+				if !strings.Contains(posted[0], listed) {
+					t.Errorf("review body doesn't list %s:\n%s", listed, posted[0])
+				}
+			}
+			if listing := strings.Contains(posted[0], "## "+i18n.T("feedback.annotations")); listing != (tt.listed != nil) {
+				t.Errorf("review body lists annotations = %v, want %v:\n%s", listing, tt.listed != nil, posted[0])
+			}
+		})
+	}
+}
+
+func TestPushModelFeedbackUpdated(t *testing.T) {
+	for _, mode := range []string{deliveryComment, deliveryReview} {
+		t.Run(mode, func(t *testing.T) {
+			f := newClassroomFixture(t, "alice")
+			f.clone()
+			viper.Set("delivery", mode)
+			classroomAPI := newFakeClassroomAPI()
+			classroomAPI.use(t)
+
+			// Pushing again must update the feedback posted before instead of posting it again
+			for run := 1; run <= 2; run++ {
+				writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), fmt.Sprintf("# Feedback\n\n- **Grade: %d**\n", run))
+				s := runModel(t, NewPushModel(f.submissions(), false))
+				if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded {
+					t.Fatalf("push %d: hw-alice = %+v", run, r)
+				}
+			}
+			if posted := classroomAPI.posted["classroom/hw-alice"]; len(posted) != 1 || !strings.Contains(posted[0], "Grade: 2") {
+				t.Errorf("posted feedback = %q, want one %s with the last grade", posted, mode)
+			}
+			m, err := loadManifest(f.submissions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if entry, _ := m.repository("hw-alice"); entry.Comment+entry.Review != 1 {
+				t.Errorf("recorded comment = %d, review = %d, want the %s 1", entry.Comment, entry.Review, mode)
+			}
+		})
+	}
+}
+
+func TestDiffAgainstStarter(t *testing.T) {
+	f := newClassroomFixture(t)
+	root := f.root
+	starter := filepath.Join(root, "starter")
+	runGit(t, "", "init", "-q", "--initial-branch=main", starter)
+	writeFile(t, filepath.Join(starter, "main.c"), "int main(void) {\n}\n")
+	runGit(t, starter, "add", ".")
+	runGit(t, starter, "commit", "-q", "-m", "Starter code")
+
+	// The student's repository started from the starter code, which the instructor changed afterward
+	student := filepath.Join(root, "submissions", "hw-alice")
+	runGit(t, "", "clone", "-q", starter, student)
+	writeFile(t, filepath.Join(student, "main.c"), "int main(void) {\n\treturn 0;\n}\n")
+	runGit(t, student, "commit", "-q", "-a", "-m", "Solve it")
+	writeFile(t, filepath.Join(starter, "README.md"), "# Homework\n")
+	runGit(t, starter, "add", ".")
+	runGit(t, starter, "commit", "-q", "-m", "Add the instructions")
+
+	patchFile := filepath.Join(root, "diff-hw-alice.patch")
+	if r := gitDiffAgainstStarter(starter, student, patchFile).run(); r.Status != StatusSucceeded {
+		t.Fatalf("diff = %+v", r)
+	}
+	patch, _ := os.ReadFile(patchFile)
+	if !strings.Contains(string(patch), "+\treturn 0;") || strings.Contains(string(patch), "README.md") {
+		t.Errorf("patch = %s, want only the student's changes", patch)
+	}
+
+	// A repository created from a template shares no history with the starter code
+	unrelated := filepath.Join(root, "submissions", "hw-bob")
+	runGit(t, "", "init", "-q", "--initial-branch=main", unrelated)
+	writeFile(t, filepath.Join(unrelated, "main.c"), "int main(void) {\n}\n")
+	writeFile(t, filepath.Join(unrelated, "README.md"), "# Homework\n")
+	runGit(t, unrelated, "add", ".")
+	runGit(t, unrelated, "commit", "-q", "-m", "Initial commit")
+	if r := gitDiffAgainstStarter(starter, unrelated, filepath.Join(root, "diff-hw-bob.patch")).run(); r.Status != StatusSucceeded || r.Detail != i18n.T("git.noChangesFromStarter") {
+		t.Errorf("diff of an unrelated repository = %+v", r)
+	}
+}
+
+func TestPullModelAuthFailure(t *testing.T) {
+	directory := t.TempDir()
+	for _, name := range []string{"hw-alice", "hw-bob"} {
+		// Just enough for the scanner to take the directories for clones
+		if err := os.MkdirAll(filepath.Join(directory, name, ".git", "objects"), 0755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, filepath.Join(directory, name, ".git", "HEAD"), "ref: refs/heads/main\n")
+	}
+	git := newFakeGitRunner()
+	git.on("pull", fakeGitOutcome{stderr: "fatal: Authentication failed for 'https://github.com/classroom/hw-alice/'", failed: true})
+	git.use(t)
+
+	s := runModel(t, NewPullModel(directory))
+	if s.count(StatusFailed) != 2 {
+		t.Errorf("pull: %s, want 2 failures", s)
+	}
+	if code := ExitCode(s.ExitError(true)); code != ExitAuthFailure {
+		t.Errorf("exit code = %d, want %d", code, ExitAuthFailure)
+	}
+}
+
+func TestRunCloneWithoutSubmissions(t *testing.T) {
+	classroomAPI := newFakeClassroomAPI()
+	classroomAPI.assignment[testAssignmentId] = classroom.Assignment{Id: 42, Slug: "hw"}
+	classroomAPI.use(t)
+
+	err := RunClone(testAssignmentId, false, NewReporter(io.Discard))
+	if err == nil || ExitCode(err) != ExitError {
+		t.Errorf("error = %v, want a fatal error", err)
+	}
+
+	classroomAPI.err = &api.HTTPError{StatusCode: http.StatusUnauthorized, Message: "Bad credentials"}
+	err = RunClone(testAssignmentId, false, NewReporter(io.Discard))
+	var e authError
+	if !errors.As(err, &e) || ExitCode(err) != ExitAuthFailure {
+		t.Errorf("error = %v, want an authentication error", err)
+	}
+}
+
+// useNativeGit makes the native backend perform the git operations until the test ends
+func (f *classroomFixture) useNativeGit() {
+	previous := gitBackend
+	gitBackend = nativeGitBackend{}
+	f.t.Cleanup(func() { gitBackend = previous })
+	// go-git doesn't read the GIT_AUTHOR_* variables, only the git config files
+	writeFile(f.t, filepath.Join(f.root, ".gitconfig"), "[user]\n\tname = Claro Test\n\temail = claro@example.com\n")
+}
+
+func TestNativeGitBackend(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob")
+	f.useNativeGit()
+	f.clone()
+	grade, err := os.ReadFile(filepath.Join(f.submissions(), "grade-hw-alice.md"))
+	if head := strings.TrimSpace(runGit(t, filepath.Join(f.submissions(), "hw-alice"), "show", "-s", "--format=%h | %ci")); err != nil || !strings.Contains(string(grade), "> Commit: "+head+"\n") {
+		t.Errorf("grade file = %q, want the commit %s", grade, head)
+	}
+
+	f.commitToRemote("hw-alice", "main.c", "int main(void) { return 0; }\n")
+	s := runModel(t, NewPullModel(f.submissions()))
+	if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded || r.Detail != "new commits" {
+		t.Errorf("pull hw-alice = %+v, want new commits", r)
+	}
+
+	writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 8**\n")
+	// Local changes that must not be committed, one of them staged
+	writeFile(t, filepath.Join(f.submissions(), "hw-alice", "README.md"), "changed by the grader\n")
+	writeFile(t, filepath.Join(f.submissions(), "hw-alice", "notes.txt"), "grader's notes\n")
+	runGit(t, filepath.Join(f.submissions(), "hw-alice"), "add", "notes.txt")
+	s = runModel(t, NewPushModel(f.submissions(), false))
+	if s.count(StatusSucceeded) != 2 {
+		t.Fatalf("push: %s", s)
+	}
+	if grading := runGit(t, "", "--git-dir", f.remote("hw-alice"), "show", "main:GRADING.md"); !strings.Contains(grading, "Grade: 8") {
+		t.Errorf("GRADING.md of hw-alice = %q", grading)
+	}
+	if files := runGit(t, "", "--git-dir", f.remote("hw-alice"), "show", "--name-only", "--format=", "main"); strings.TrimSpace(files) != "GRADING.md" {
+		t.Errorf("files committed = %q, want only GRADING.md", files)
+	}
+	if status := runGit(t, filepath.Join(f.submissions(), "hw-alice"), "status", "--porcelain"); status != " M README.md\nA  notes.txt\n" {
+		t.Errorf("status after the push = %q, want the grader's changes kept", status)
+	}
+}
+
+func TestNativePullOverLocalCommit(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	f.useNativeGit()
+	f.clone()
+	f.commitToRemote("hw-alice", "main.c", "int main(void) { return 0; }\n")
+	writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 8**\n")
+	// The grade is committed, but the push is rejected
+	_ = gitCommitAndPush(filepath.Join(f.submissions(), "hw-alice"), gradeFilePair(t, f.submissions(), "hw-alice")).run()
+
+	r := gitPull(filepath.Join(f.submissions(), "hw-alice")).run()
+	if r.Status != StatusFailed || r.Error != i18n.T("git.errDiverged", "pull") {
+		t.Errorf("pull = %+v, want a failure suggesting the exec backend", r)
+	}
+}
+
+func TestPushRejected(t *testing.T) {
+	for _, backend := range []string{GitBackendExec, GitBackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			f := newClassroomFixture(t, "alice")
+			if backend == GitBackendNative {
+				f.useNativeGit()
+			}
+			f.clone()
+			// The student pushed after the repository was cloned
+			f.commitToRemote("hw-alice", "main.c", "int main(void) { return 0; }\n")
+			writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 8**\n")
+
+			r := gitCommitAndPush(filepath.Join(f.submissions(), "hw-alice"), gradeFilePair(t, f.submissions(), "hw-alice")).run()
+			if r.Status != StatusFailed || r.Error != i18n.T("git.errNonFastForward", "push") {
+				t.Errorf("push = %+v, want a non-fast-forward failure", r)
+			}
+		})
+	}
+}
+
+func TestCloneNotFound(t *testing.T) {
+	for _, backend := range []string{GitBackendExec, GitBackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			f := newClassroomFixture(t, "alice")
+			if backend == GitBackendNative {
+				f.useNativeGit()
+			}
+			if err := os.RemoveAll(f.remote("hw-alice")); err != nil {
+				t.Fatal(err)
+			}
+			s := runModel(t, NewCloneModel(testAssignmentId, false))
+			if r := result(t, s, "hw-alice"); r.Status != StatusFailed || !strings.Contains(r.Error, i18n.T("git.errNotFound", "clone")) {
+				t.Errorf("clone = %+v, want a repository not found failure", r)
+			}
+		})
+	}
+}
+
+// gradeFilePair returns the repository and grade file of the submissions directory, as the push model pairs them
+func gradeFilePair(t *testing.T, directory string, name string) pair {
+	t.Helper()
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p pair
+	for _, e := range entries {
+		switch e.Name() {
+		case name:
+			p.repository = e
+		case "grade-" + name + ".md":
+			p.gradeFilename = e
+		}
+	}
+	return p
+}
+
+func TestScanSubmissions(t *testing.T) {
+	f := newClassroomFixture(t, "alice", "bob", "carol")
+	f.clone()
+	directory := f.submissions()
+	// A linked worktree, a submodule, a bare repository, a directory that isn't a repository and a
+	// clone without a grade file
+	runGit(t, filepath.Join(directory, "hw-alice"), "worktree", "add", "-q", filepath.Join(directory, "hw-alice-review"))
+	writeFile(t, filepath.Join(directory, "grade-hw-alice-review.md"), "# Feedback\n")
+	runGit(t, filepath.Join(directory, "hw-bob"), "-c", "protocol.file.allow=always", "submodule", "add", "-q", f.remote("hw-carol"), "lib")
+	runGit(t, "", "clone", "-q", "--bare", f.remote("hw-carol"), filepath.Join(directory, "hw-dave"))
+	if err := os.Mkdir(filepath.Join(directory, "notes"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(directory, "grade-hw-carol.md")); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := scanSubmissions(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, r := range s.repositories {
+		names = append(names, r.Name())
+	}
+	if strings.Join(names, " ") != "hw-alice hw-alice-review hw-bob hw-carol" {
+		t.Errorf("repositories = %v", names)
+	}
+	lib, err := scanSubmissions(filepath.Join(directory, "hw-bob"))
+	if err != nil || len(lib.repositories) != 1 || lib.repositories[0].Name() != "lib" {
+		t.Errorf("submodules of hw-bob = %v, %v, want lib", lib.repositories, err)
+	}
+	repos, err := repositoriesAndGradeFiles(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+	skipped := make(map[string]string)
+	for _, d := range repos.skipped {
+		skipped[d.name] = d.reason
+	}
+	want := map[string]string{
+		".claro":   i18n.T("dir.stateDir"),
+		"hw-carol": i18n.T("dir.noGradeFile", "grade-hw-carol.md"),
+		"hw-dave":  i18n.T("dir.bare"),
+		"notes":    i18n.T("dir.notARepository"),
+	}
+	for name, reason := range want {
+		if skipped[name] != reason {
+			t.Errorf("%s skipped because %q, want %q", name, skipped[name], reason)
+		}
+	}
+	if len(repos.repositories) != 3 {
+		t.Errorf("repositories with a grade file = %d, want 3", len(repos.repositories))
+	}
+}
+
+func TestCommitAuthorAndSigning(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen is not installed")
+	}
+	for _, backend := range []string{GitBackendExec, GitBackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			f := newClassroomFixture(t, "alice")
+			f.useNativeGit()
+			if backend == GitBackendExec {
+				gitBackend = execGitBackend{}
+			}
+			f.clone()
+			// The GIT_AUTHOR_* variables would take precedence over the identity given to git
+			for _, v := range []string{"GIT_AUTHOR_NAME", "GIT_AUTHOR_EMAIL", "GIT_COMMITTER_NAME", "GIT_COMMITTER_EMAIL"} {
+				t.Setenv(v, "")
+				_ = os.Unsetenv(v)
+			}
+			key := filepath.Join(f.root, "signing")
+			if out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "", "-f", key).CombinedOutput(); err != nil {
+				t.Fatalf("ssh-keygen: %s", out)
+			}
+			publicKey, err := os.ReadFile(key + ".pub")
+			if err != nil {
+				t.Fatal(err)
+			}
+			viper.Set("authorname", "Grading Bot")
+			viper.Set("authoremail", "grading@example.edu")
+			viper.Set("signing", signingSSH)
+			viper.Set("signingkey", key)
+			viper.Set("grader", "Ana Assistant <ana@example.edu>")
+			writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 8**\n")
+
+			r := gitCommitAndPush(filepath.Join(f.submissions(), "hw-alice"), gradeFilePair(t, f.submissions(), "hw-alice")).run()
+			if r.Status != StatusSucceeded {
+				t.Fatalf("push = %+v", r)
+			}
+			commit := runGit(t, "", "--git-dir", f.remote("hw-alice"), "log", "-1", "--format=%an <%ae>%n%cn <%ce>%n%B", "main")
+			want := "Grading Bot <grading@example.edu>\nGrading Bot <grading@example.edu>\nGraded\n\nCo-authored-by: Ana Assistant <ana@example.edu>"
+			if strings.TrimSpace(commit) != want {
+				t.Errorf("commit = %q, want %q", commit, want)
+			}
+			allowedSigners := filepath.Join(f.root, "allowed_signers")
+			writeFile(t, allowedSigners, "grading@example.edu "+string(publicKey))
+			runGit(t, "", "--git-dir", f.remote("hw-alice"), "-c", "gpg.ssh.allowedSignersFile="+allowedSigners, "verify-commit", "main")
+		})
+	}
+}
+
+func TestCommitMessageTemplate(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	f.clone()
+	viper.Set("message", "{{.Assignment}} graded: {{.Grade}}/10\n\nStudent: {{.Student}}, commit {{.Commit}}, graded by {{.Grader}}")
+	viper.Set("grader", "Ana Assistant <ana@example.edu>")
+	gradeFile := filepath.Join(f.submissions(), "grade-hw-alice.md")
+	if err := writeGradeValue(gradeFile, "8.5"); err != nil {
+		t.Fatal(err)
+	}
+	graded := strings.TrimSpace(runGit(t, filepath.Join(f.submissions(), "hw-alice"), "show", "-s", "--format=%h"))
+
+	r := gitCommitAndPush(filepath.Join(f.submissions(), "hw-alice"), gradeFilePair(t, f.submissions(), "hw-alice")).run()
+	if r.Status != StatusSucceeded {
+		t.Fatalf("push = %+v", r)
+	}
+	message := runGit(t, "", "--git-dir", f.remote("hw-alice"), "log", "-1", "--format=%B", "main")
+	want := "Homework graded: 8.5/10\n\nStudent: alice, commit " + graded + ", graded by Ana Assistant\n\nCo-authored-by: Ana Assistant <ana@example.edu>"
+	if strings.TrimSpace(message) != want {
+		t.Errorf("commit message = %q, want %q", message, want)
+	}
+
+	if err := checkConfigValue("message", "{{.Score}} points"); err == nil {
+		t.Error("a template with an unknown field was accepted")
+	}
+}
+
+func TestGradeTag(t *testing.T) {
+	for _, backend := range []string{GitBackendExec, GitBackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			f := newClassroomFixture(t, "alice", "bob")
+			if backend == GitBackendNative {
+				f.useNativeGit()
+			}
+			f.clone()
+			viper.Set("gradetag", true)
+			for _, name := range []string{"hw-alice", "hw-bob"} {
+				writeFile(t, filepath.Join(f.submissions(), name, "local.txt"), "not committed\n")
+				graded := strings.TrimSpace(runGit(t, filepath.Join(f.submissions(), name), "rev-parse", "HEAD"))
+				r := gitCommitAndPush(filepath.Join(f.submissions(), name), gradeFilePair(t, f.submissions(), name)).run()
+				if r.Status != StatusSucceeded {
+					t.Fatalf("push %s = %+v", name, r)
+				}
+				if tagged := runGit(t, "", "--git-dir", f.remote(name), "rev-parse", "graded/hw^{commit}"); strings.TrimSpace(tagged) != graded {
+					t.Errorf("graded/hw of %s = %s, want the graded commit %s", name, tagged, graded)
+				}
+			}
+
+			// Alice resubmits after being graded
+			f.commitToRemote("hw-alice", "main.c", "int main(void) { return 1; }\n")
+			if s := runModel(t, NewPullModel(f.submissions())); s.count(StatusSucceeded) != 2 {
+				t.Fatalf("pull: %s", s)
+			}
+			var out bytes.Buffer
+			if err := RegradedSince(f.submissions(), &out); err != nil {
+				t.Fatal(err)
+			}
+			if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 1 || !strings.HasPrefix(lines[0], "hw-alice") || !strings.Contains(lines[0], "1 commits") {
+				t.Errorf("regraded-since = %q, want only hw-alice with 1 commit", out.String())
+			}
+
+			// Regrading moves the tag to the new graded commit
+			if err := writeGradeValue(filepath.Join(f.submissions(), "grade-hw-alice.md"), "9"); err != nil {
+				t.Fatal(err)
+			}
+			head := strings.TrimSpace(runGit(t, filepath.Join(f.submissions(), "hw-alice"), "rev-parse", "--short", "HEAD"))
+			gradeFile := filepath.Join(f.submissions(), "grade-hw-alice.md")
+			content, _ := os.ReadFile(gradeFile)
+			writeFile(t, gradeFile, regexp.MustCompile(`(?m)^> Commit: [0-9a-f]+`).ReplaceAllString(string(content), "> Commit: "+head))
+			if r := gitCommitAndPush(filepath.Join(f.submissions(), "hw-alice"), gradeFilePair(t, f.submissions(), "hw-alice")).run(); r.Status != StatusSucceeded {
+				t.Fatalf("regrade hw-alice = %+v", r)
+			}
+			out.Reset()
+			if err := RegradedSince(f.submissions(), &out); err != nil || strings.TrimSpace(out.String()) != i18n.T("regraded.none", "graded/hw") {
+				t.Errorf("regraded-since after the regrade = %q, %v", out.String(), err)
+			}
+		})
+	}
+}
+
+func TestGradeTagAPIDelivery(t *testing.T) {
+	for _, backend := range []string{GitBackendExec, GitBackendNative} {
+		t.Run(backend, func(t *testing.T) {
+			f := newClassroomFixture(t, "alice")
+			if backend == GitBackendNative {
+				f.useNativeGit()
+			}
+			f.clone()
+			writeFile(t, filepath.Join(f.submissions(), "grade-hw-alice.md"), "# Feedback\n\n- **Grade: 7**\n")
+			viper.Set("gradetag", true)
+			viper.Set("delivery", deliveryReview)
+			classroomAPI := newFakeClassroomAPI()
+			classroomAPI.use(t)
+
+			graded := strings.TrimSpace(runGit(t, filepath.Join(f.submissions(), "hw-alice"), "rev-parse", "HEAD"))
+			s := runModel(t, NewPushModel(f.submissions(), false))
+			if r := result(t, s, "hw-alice"); r.Status != StatusSucceeded {
+				t.Fatalf("hw-alice = %+v", r)
+			}
+			if tagged := runGit(t, "", "--git-dir", f.remote("hw-alice"), "rev-parse", "graded/hw^{commit}"); strings.TrimSpace(tagged) != graded {
+				t.Errorf("graded/hw = %s, want the graded commit %s", tagged, graded)
+			}
+			if posted := classroomAPI.posted["classroom/hw-alice"]; len(posted) != 1 {
+				t.Errorf("posted feedback = %q", posted)
+			}
+			if log := runGit(t, "", "--git-dir", f.remote("hw-alice"), "log", "--format=%s", "main"); strings.Contains(log, "Graded") {
+				t.Errorf("the grading file was committed: %q", log)
+			}
+		})
+	}
+}
+
+func TestGroupAssignment(t *testing.T) {
+	f := newClassroomFixture(t, "rockets")
+	f.assignment.AssignmentType = "group"
+	f.accepted[0].Assignment = f.assignment
+	f.accepted[0].Students = []classroom.Student{{Login: "alice"}, {Login: "bob"}, {Login: "carol"}}
+	// Alice commits with her GitHub noreply email, Bob with his login as name, and someone else helps
+	authors := []struct{ name, email, file, content string }{
+		{"Alice Doe", "1234+alice@users.noreply.github.com", "main.c", "int main(void) {\n\treturn 0;\n}\n"},
+		{"bob", "bob.smith@example.com", "util.c", "int one(void) { return 1; }\n"},
+		{"Alice Doe", "1234+alice@users.noreply.github.com", "main.c", "int main(void) {\n\treturn 1;\n}\n"},
+		{"Dave Helper", "dave@example.com", "notes.txt", "help\n"},
+	}
+	for _, a := range authors {
+		t.Setenv("GIT_AUTHOR_NAME", a.name)
+		t.Setenv("GIT_AUTHOR_EMAIL", a.email)
+		f.commitToRemote("hw-rockets", a.file, a.content)
+	}
+	t.Setenv("GIT_AUTHOR_NAME", "Claro Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "claro@example.com")
+	f.clone()
+
+	m, err := loadManifest(f.submissions())
+	if entry, ok := m.repository("hw-rockets"); err != nil || !ok || entry.Team != "rockets" || strings.Join(entry.Students, ",") != "alice,bob,carol" || m.Assignment.Type != "group" {
+		t.Errorf("manifest = %+v, %v, want the team rockets of alice, bob and carol", m, err)
+	}
+
+	gradeFile := filepath.Join(f.submissions(), "grade-hw-rockets.md")
+	content, _ := os.ReadFile(gradeFile)
+	graded := strings.Replace(string(content), "- **@bob: **", "- **@bob: +0.5**", 1)
+	graded = strings.Replace(graded, "- **@carol: **", "- **@carol:** 4", 1)
+	writeFile(t, gradeFile, graded)
+	if err = writeGradeValue(gradeFile, "8/10"); err != nil {
+		t.Fatal(err)
+	}
+	grades, err := readMemberGrades(gradeFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []memberGrade{{"alice", "", "8/10"}, {"bob", "+0.5", "8.5/10"}, {"carol", "4", "4"}}
+	if !reflect.DeepEqual(grades, want) {
+		t.Errorf("member grades = %+v, want %+v", grades, want)
+	}
+
+	var out bytes.Buffer
+	if err = RunContributions(f.submissions(), true, NewReporter(&out)); err != nil {
+		t.Fatalf("contributions: %v\n%s", err, out.String())
+	}
+	report, _ := os.ReadFile(filepath.Join(f.submissions(), "contributions-hw-rockets.md"))
+	for _, row := range []string{"| @alice | 2 | 4 | 1 |", "| @bob | 1 | 1 | 0 |", "| @carol | 0 | 0 | 0 |", "| Dave Helper <dave@example.com> | 1 | 1 | 0 |"} {
+		if !strings.Contains(string(report), row) {
+			t.Errorf("contribution report doesn't contain %q:\n%s", row, report)
+		}
+	}
+	// Running it again replaces the report in the grade file
+	if err = RunContributions(f.submissions(), true, NewReporter(&out)); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(gradeFile)
+	if strings.Count(string(content), "## Contributions") != 1 || !strings.Contains(string(content), "- **@bob: +0.5**") {
+		t.Errorf("grade file = %s", content)
+	}
+}
+
+func TestGradeLabelChanged(t *testing.T) {
+	f := newClassroomFixture(t, "alice")
+	f.clone()
+	gradeFile := filepath.Join(f.submissions(), "grade-hw-alice.md")
+	if err := writeGradeValue(gradeFile, "8"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The grade files written with the previous grade string are still read and updated in place
+	viper.Set("grade", "Nota: ")
+	if grade, err := readGradeValue(gradeFile); err != nil || grade != "8" {
+		t.Fatalf("grade after the grade string changed = %q, %v, want 8", grade, err)
+	}
+	if err := writeGradeValue(gradeFile, "9"); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(gradeFile)
+	if !strings.Contains(string(content), "- **Grade: 9**") || strings.Contains(string(content), "Nota:") {
+		t.Errorf("grade file:\n%s", content)
+	}
+}
+
+func TestExportGrades(t *testing.T) {
+	f := newClassroomFixture(t, "ana", "bruno", "caio", "davi")
+	f.clone()
+	for student, grade := range map[string]string{"ana": "9/10", "bruno": "B", "caio": "7.5"} {
+		if err := writeGradeValue(filepath.Join(f.submissions(), "grade-hw-"+student+".md"), grade); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Without a mapping file, one listing the students is written
+	var out bytes.Buffer
+	if err := ExportGrades(f.submissions(), ExportMoodle, "", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	template, _ := os.ReadFile(filepath.Join(f.submissions(), studentMappingFilename))
+	if string(template) != "login,id,email,name\nana,,,\nbruno,,,\ncaio,,,\ndavi,,,\n" {
+		t.Errorf("mapping template = %q", template)
+	}
+
+	// Until the template is filled in, every graded student is reported
+	out.Reset()
+	if err := ExportGrades(f.submissions(), ExportMoodle, "", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, report := range []string{i18n.T("export.written", 0, filepath.Join(f.submissions(), "grades-moodle.csv")),
+		i18n.T("export.unmapped", 3, "ana, bruno, caio"), i18n.T("export.ungraded", 1, "davi")} {
+		if !strings.Contains(out.String(), report) {
+			t.Errorf("export doesn't report %q:\n%s", report, out.String())
+		}
+	}
+
+	// The mapping file of the course is found in the parent directory; caio is missing from it
+	_ = os.Remove(filepath.Join(f.submissions(), studentMappingFilename))
+	writeFile(t, filepath.Join(filepath.Dir(f.submissions()), studentMappingFilename),
+		"name,login,id,email\nAna Lima,Ana,2024001,ana@example.edu\n\"Souza, Bruno\",bruno,2024002,bruno@example.edu\nDavi Melo,davi,2024004,davi@example.edu\n")
+	out.Reset()
+	if err := ExportGrades(f.submissions(), ExportMoodle, "", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), i18n.T("export.unmapped", 1, "caio")) || !strings.Contains(out.String(), i18n.T("export.ungraded", 1, "davi")) {
+		t.Errorf("unmapped and ungraded students not reported:\n%s", out.String())
+	}
+	moodle, _ := os.ReadFile(filepath.Join(f.submissions(), "grades-moodle.csv"))
+	if want := "ID number,Email address,Homework\n2024001,ana@example.edu,9\n2024002,bruno@example.edu,B\n"; string(moodle) != want {
+		t.Errorf("moodle export = %q, want %q", moodle, want)
+	}
+
+	canvasFile := filepath.Join(f.root, "canvas.csv")
+	if err := ExportGrades(f.submissions(), ExportCanvas, "", canvasFile, &out); err != nil {
+		t.Fatal(err)
+	}
+	canvas, _ := os.ReadFile(canvasFile)
+	if !strings.Contains(string(canvas), "\"Souza, Bruno\",,2024002,bruno@example.edu,,B\n") {
+		t.Errorf("canvas export = %s", canvas)
+	}
+
+	// The spreadsheet lists every student, with the numeric grades as numbers
+	if err := ExportGrades(f.submissions(), ExportXLSX, "", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	z, err := zip.OpenReader(filepath.Join(f.submissions(), "grades.xlsx"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(z *zip.ReadCloser) {
+		_ = z.Close()
+	}(z)
+	sheet, err := z.Open("xl/worksheets/sheet1.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, _ := io.ReadAll(sheet)
+	for _, cell := range []string{`<c r="F2"><v>9</v></c>`, `<c r="A4" t="inlineStr"><is><t>caio</t></is></c>`, `<c r="F4"><v>7.5</v></c>`} {
+		if !strings.Contains(string(content), cell) {
+			t.Errorf("sheet doesn't contain %s:\n%s", cell, content)
+		}
+	}
+	if strings.Contains(string(content), "davi") {
+		t.Errorf("sheet lists the ungraded student:\n%s", content)
+	}
+
+	if err = ExportGrades(f.submissions(), "sigaa", "", "", &out); err == nil {
+		t.Error("invalid format accepted")
+	}
+}
+
+func TestRoster(t *testing.T) {
+	f := newClassroomFixture(t, "xX_coder_Xx", "bruno")
+	for i := range f.accepted {
+		f.accepted[i].CommitCount = 2
+	}
+	// The roster exported by GitHub Classroom, with a student who hasn't linked a GitHub account yet
+	rosterFile := filepath.Join(f.root, "classroom_roster.csv")
+	writeFile(t, rosterFile, "\"identifier\",\"github_username\",\"github_id\",\"name\"\n"+
+		"\"ana@example.edu\",\"xX_coder_Xx\",\"101\",\"Ana Lima\"\n"+
+		"\"bruno@example.edu\",\"bruno\",\"102\",\"Bruno Souza\"\n"+
+		"\"carla@example.edu\",\"\",\"\",\"Carla Dias\"\n"+
+		"\"dan@example.edu\",\"dan\",\"104\",\"Dan Reis\"\n")
+	var out bytes.Buffer
+	if err := ImportRoster(rosterFile, "", &out); err != nil {
+		t.Fatal(err)
+	}
+	students, err := readStudents(courseRosterPath(""))
+	if err != nil || len(students) != 4 || students[0] != (student{"xX_coder_Xx", "ana@example.edu", "ana@example.edu", "Ana Lima"}) {
+		t.Fatalf("roster = %+v, %v", students, err)
+	}
+
+	f.clone()
+	if r := repositoryResult(actionPull, filepath.Join(f.submissions(), "hw-xX_coder_Xx")); r.Name != "Ana Lima" || !strings.Contains(r.String(), "hw-xX_coder_Xx (Ana Lima)") {
+		t.Errorf("result = %+v", r)
+	}
+	content, _ := os.ReadFile(filepath.Join(f.submissions(), "grade-hw-bruno.md"))
+	if !strings.Contains(string(content), "\n> Student: Bruno Souza (bruno@example.edu)\n") {
+		t.Errorf("grade file header doesn't name the student:\n%s", content)
+	}
+
+	out.Reset()
+	if err = RosterMissing(f.submissions(), false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if s := out.String(); !strings.Contains(s, "Carla Dias") || !strings.Contains(s, "@dan") || strings.Contains(s, "Ana Lima") || strings.Contains(s, "Bruno Souza") {
+		t.Errorf("missing students:\n%s", s)
+	}
+
+	// The export finds the roster without a mapping file
+	if err = writeGradeValue(filepath.Join(f.submissions(), "grade-hw-xX_coder_Xx.md"), "10"); err != nil {
+		t.Fatal(err)
+	}
+	if err = ExportGrades(f.submissions(), ExportGoogle, "", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	google, _ := os.ReadFile(filepath.Join(f.submissions(), "grades-google.csv"))
+	if want := "Email,Name,Homework\nana@example.edu,Ana Lima,10\n"; string(google) != want {
+		t.Errorf("google export = %q, want %q", google, want)
+	}
+	if !strings.Contains(out.String(), i18n.T("export.ungraded", 1, "bruno")) {
+		t.Errorf("ungraded student not reported:\n%s", out.String())
+	}
+}
+
+func TestRosterInCourseDirectory(t *testing.T) {
+	f := newClassroomFixture(t, "ana")
+	f.accepted[0].CommitCount = 2
+	f.clone()
+	submissions := f.submissions()
+	courseDirectory := filepath.Dir(submissions)
+	writeFile(t, filepath.Join(f.root, "roster.csv"), "login,id,email,name\nana,2024001,ana@example.edu,Ana Lima\n")
+	viper.Set("cloneroot", "")
+	var out bytes.Buffer
+	if err := ImportRoster(filepath.Join(f.root, "roster.csv"), "", &out); err == nil {
+		t.Error("roster imported without a course directory or clone root")
+	}
+	if err := ImportRoster(filepath.Join(f.root, "roster.csv"), courseDirectory, &out); err != nil {
+		t.Fatal(err)
+	}
+
+	// The roster is found from the submissions directory, whatever the working directory
+	wd, _ := os.Getwd()
+	if err := os.Chdir(submissions); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	if r := repositoryResult(actionPull, filepath.Join(submissions, "hw-ana")); r.Name != "Ana Lima" {
+		t.Errorf("result = %+v, want the student's name", r)
+	}
+	out.Reset()
+	if err := RosterMissing(".", false, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), i18n.T("roster.allAccepted", 1)) {
+		t.Errorf("missing students:\n%s", out.String())
+	}
+}
+
+func TestZeroGrades(t *testing.T) {
+	f := newClassroomFixture(t, "ana", "bruno")
+	f.accepted[0].CommitCount = 3
+	f.clone()
+	writeFile(t, filepath.Join(f.root, "roster.csv"), "login,id,email,name\n"+
+		"ana,2024001,ana@example.edu,Ana Lima\n"+
+		"bruno,2024002,bruno@example.edu,Bruno Souza\n"+
+		",2024003,carla@example.edu,Carla Dias\n"+
+		"dan,2024004,dan@example.edu,Dan Reis\n")
+	var out bytes.Buffer
+	if err := ImportRoster(filepath.Join(f.root, "roster.csv"), "", &out); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeGradeValue(filepath.Join(f.submissions(), "grade-hw-ana.md"), "9"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Bruno accepted without pushing, Carla has no GitHub account and Dan never accepted
+	absent, total, err := absentStudents(f.submissions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reasons []string
+	for _, a := range absent {
+		reasons = append(reasons, a.Name+":"+a.Reason+":"+a.Repository)
+	}
+	want := []string{"Bruno Souza:no_commits:hw-bruno", "Carla Dias:no_login:", "Dan Reis:not_accepted:"}
+	if total != 4 || !reflect.DeepEqual(reasons, want) {
+		t.Errorf("absent students = %v of %d, want %v of 4", reasons, total, want)
+	}
+
+	out.Reset()
+	if err = RosterMissing(f.submissions(), true, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), i18n.T("absent.zeroGrades", 3)) {
+		t.Errorf("report:\n%s", out.String())
+	}
+	content, _ := os.ReadFile(filepath.Join(f.submissions(), "grade-hw-2024003.md"))
+	if !strings.Contains(string(content), "> Student: Carla Dias (2024003)\n") || !strings.Contains(string(content), "- **Grade: 0**") {
+		t.Errorf("zero grade file:\n%s", content)
+	}
+	// A grade written by the grader is kept
+	if err = writeGradeValue(filepath.Join(f.submissions(), "grade-hw-bruno.md"), "5"); err != nil {
+		t.Fatal(err)
+	}
+	if err = RosterMissing(f.submissions(), true, &out); err != nil {
+		t.Fatal(err)
+	}
+	if grade, _ := readGradeValue(filepath.Join(f.submissions(), "grade-hw-bruno.md")); grade != "5" {
+		t.Errorf("bruno's grade = %q, want 5", grade)
+	}
+
+	// The students without a submission are exported with their zero grade
+	if err = ExportGrades(f.submissions(), ExportMoodle, "", "", &out); err != nil {
+		t.Fatal(err)
+	}
+	moodle, _ := os.ReadFile(filepath.Join(f.submissions(), "grades-moodle.csv"))
+	if want := "ID number,Email address,Homework\n2024003,carla@example.edu,0\n2024001,ana@example.edu,9\n2024002,bruno@example.edu,5\n2024004,dan@example.edu,0\n"; string(moodle) != want {
+		t.Errorf("moodle export = %q, want %q", moodle, want)
+	}
+
+	// Once Dan accepts, the grade file of his repository replaces his zero grade
+	f.accept("dan")
+	s := runModel(t, NewCloneModel(testAssignmentId, false))
+	if r := result(t, s, "hw-dan"); r.Status != StatusSucceeded || r.Detail != i18n.T("git.zeroGradeReplaced") {
+		t.Fatalf("hw-dan = %+v", r)
+	}
+	content, _ = os.ReadFile(filepath.Join(f.submissions(), "grade-hw-dan.md"))
+	if grade, _ := readGradeValue(filepath.Join(f.submissions(), "grade-hw-dan.md")); !strings.Contains(string(content), "> Commit: ") || grade != "" {
+		t.Errorf("grade file of the accepted assignment:\n%s", content)
+	}
+}
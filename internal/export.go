@@ -0,0 +1,365 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"archive/zip"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+)
+
+// Export formats
+const (
+	ExportMoodle = "moodle"
+	ExportCanvas = "canvas"
+	ExportGoogle = "google"
+	ExportXLSX   = "xlsx"
+)
+
+// ExportFormats are the formats grades can be exported to
+var ExportFormats = []string{ExportMoodle, ExportCanvas, ExportGoogle, ExportXLSX}
+
+// studentMappingFilename is the file mapping the students' GitHub logins to their institutional
+// identity, looked up in the submissions directory and in its parent course directory. The course
+// roster is kept in the same format.
+const studentMappingFilename = "students.csv"
+
+// student is a student's institutional identity, mapped from their GitHub login
+type student struct {
+	Login string `json:"login"`
+	ID    string `json:"id"`
+	Email string `json:"email"`
+	Name  string `json:"name"`
+}
+
+// loadStudentMapping reads the CSV file mapping the students' GitHub logins to their institutional
+// identity, by login in lower case
+func loadStudentMapping(path string) (map[string]student, error) {
+	students, err := readStudents(path)
+	if err != nil {
+		return nil, err
+	}
+	mapping := make(map[string]student)
+	for _, s := range students {
+		if s.Login != "" {
+			mapping[strings.ToLower(s.Login)] = s
+			continue
+		}
+		// Students who haven't linked a GitHub account are found by the ID or the email naming their
+		// zero grade file
+		for _, key := range []string{s.ID, s.Email} {
+			if key != "" {
+				mapping[strings.ToLower(key)] = s
+			}
+		}
+	}
+	return mapping, nil
+}
+
+// findStudentMapping returns the student mapping file of the submissions directory, in the directory
+// itself or in its parent course directory, or the roster imported to the clone root. It returns "" if
+// there is none.
+func findStudentMapping(submissionsDirectory string) string {
+	directory, _ := filepath.Abs(expandHomeDirectory(submissionsDirectory))
+	for _, path := range []string{
+		filepath.Join(directory, studentMappingFilename),
+		filepath.Join(filepath.Dir(directory), studentMappingFilename),
+		courseRosterPath(""),
+	} {
+		if _, err := os.Stat(path); path != "" && err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// studentGrade is the grade of a student, as written in the grade file of their repository
+type studentGrade struct {
+	student
+	Repository string
+	Grade      string
+}
+
+// collectGrades returns the grade of each student with a grade file in the submissions directory,
+// including the students given a zero grade without a repository. Each member of a team gets the
+// team's grade with their adjustment.
+func collectGrades(directory string) ([]studentGrade, error) {
+	s, err := scanSubmissions(directory)
+	if err != nil {
+		return nil, err
+	}
+	m, _ := loadManifest(directory)
+	repositories := make(map[string]bool)
+	for _, entry := range s.repositories {
+		repositories[entry.Name()] = true
+	}
+	names := slices.Sorted(maps.Keys(s.gradeFiles))
+	var grades []studentGrade
+	for _, name := range names {
+		repository := ""
+		if _, ok := m.repository(name); ok || repositories[name] {
+			repository = name
+		}
+		path := filepath.Join(directory, s.gradeFiles[name].Name())
+		members, err := readMemberGrades(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(members) > 0 {
+			for _, member := range members {
+				grades = append(grades, studentGrade{student: student{Login: member.Login}, Repository: repository, Grade: member.Grade})
+			}
+			continue
+		}
+		grade, err := readGradeValue(path)
+		if err != nil {
+			return nil, err
+		}
+		// Without a repository, the grade file is named after the student's login, ID or email
+		logins := []string{strings.TrimPrefix(name, m.Assignment.Slug+"-")}
+		if r, ok := m.repository(name); ok && len(r.Students) > 0 {
+			logins = r.Students
+		}
+		for _, login := range logins {
+			grades = append(grades, studentGrade{student: student{Login: login}, Repository: repository, Grade: grade})
+		}
+	}
+	return grades, nil
+}
+
+// exportValue returns the grade as a number when it starts with one, such as 8.5 for "8.5/10", as the
+// gradebooks only import numbers. Other grades, such as letter grades, are exported as written.
+func exportValue(grade string) string {
+	if value, ok := parseGradeNumber(grade); ok {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return grade
+}
+
+// identifiable reports whether the gradebook of the format can match the student: Google Classroom
+// matches the students by their email, Moodle and Canvas by their ID or email, while the spreadsheet
+// lists every student
+func identifiable(format string, s student) bool {
+	switch format {
+	case ExportXLSX:
+		return true
+	case ExportGoogle:
+		return s.Email != ""
+	}
+	return s.ID != "" || s.Email != ""
+}
+
+// ExportGrades writes the grades of the students in the submissions directory to the file, in an LMS
+// import format. The students are identified by the mapping file, or by the students.csv file found
+// next to the submissions directory. Students the format can't identify, as they are missing from the
+// mapping or lack an ID or email in it, are left out and reported, as are the students without a grade.
+// Without a mapping file, one listing the students is written to the submissions directory.
+func ExportGrades(directory string, format string, mappingFile string, outputFile string, w io.Writer) error {
+	if !slices.Contains(ExportFormats, format) {
+		return errors.New(i18n.T("export.invalidFormat", format, strings.Join(ExportFormats, ", ")))
+	}
+	directory, _ = filepath.Abs(expandHomeDirectory(directory))
+	grades, err := collectGrades(directory)
+	if err != nil {
+		return err
+	}
+	if len(grades) == 0 {
+		return errors.New(i18n.T("dir.noGradeFiles", directory))
+	}
+	if mappingFile == "" {
+		mappingFile = findStudentMapping(directory)
+	}
+	mapping := map[string]student{}
+	if mappingFile != "" {
+		if mapping, err = loadStudentMapping(expandHomeDirectory(mappingFile)); err != nil {
+			return err
+		}
+	}
+	var exported []studentGrade
+	var unmapped, ungraded []string
+	for _, g := range grades {
+		s, mapped := mapping[strings.ToLower(g.Login)]
+		if mapped {
+			g.student = s
+		}
+		switch {
+		case g.Grade == "":
+			// An empty cell would clear the grade already in the gradebook
+			ungraded = append(ungraded, g.Login)
+		case !identifiable(format, g.student):
+			unmapped = append(unmapped, g.Login)
+		default:
+			if !mapped {
+				unmapped = append(unmapped, g.Login)
+			}
+			exported = append(exported, g)
+		}
+	}
+
+	m, _ := loadManifest(directory)
+	title := m.Assignment.Title
+	if title == "" {
+		title = filepath.Base(directory)
+	}
+	if outputFile == "" {
+		outputFile = filepath.Join(directory, "grades-"+format+".csv")
+		if format == ExportXLSX {
+			outputFile = filepath.Join(directory, "grades.xlsx")
+		}
+	}
+	f, err := os.Create(expandHomeDirectory(outputFile))
+	if err != nil {
+		return err
+	}
+	switch format {
+	case ExportMoodle:
+		err = writeMoodleCSV(f, title, exported)
+	case ExportCanvas:
+		err = writeCanvasCSV(f, title, exported)
+	case ExportGoogle:
+		err = writeGoogleCSV(f, title, exported)
+	case ExportXLSX:
+		err = writeXLSX(f, title, exported)
+	}
+	if e := f.Close(); err == nil {
+		err = e
+	}
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, i18n.T("export.written", len(exported), outputFile))
+	if len(unmapped) > 0 && format == ExportXLSX {
+		_, _ = fmt.Fprintln(w, i18n.T("export.unmappedListed", len(unmapped), strings.Join(unmapped, ", ")))
+	} else if len(unmapped) > 0 {
+		_, _ = fmt.Fprintln(w, i18n.T("export.unmapped", len(unmapped), strings.Join(unmapped, ", ")))
+	}
+	if len(ungraded) > 0 {
+		_, _ = fmt.Fprintln(w, i18n.T("export.ungraded", len(ungraded), strings.Join(ungraded, ", ")))
+	}
+	if mappingFile == "" {
+		// A mapping file listing the students is written for the teacher to fill in
+		path := filepath.Join(directory, studentMappingFilename)
+		var students []student
+		for _, g := range grades {
+			students = append(students, student{Login: g.Login})
+		}
+		if err = writeStudents(path, students); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(w, i18n.T("export.mappingTemplate", path))
+	}
+	return nil
+}
+
+// writeMoodleCSV writes the grades in the CSV format of Moodle's grade import, matching the students
+// by their ID number or email address
+func writeMoodleCSV(w io.Writer, title string, grades []studentGrade) error {
+	c := csv.NewWriter(w)
+	_ = c.Write([]string{"ID number", "Email address", title})
+	for _, g := range grades {
+		_ = c.Write([]string{g.ID, g.Email, exportValue(g.Grade)})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// writeCanvasCSV writes the grades in the CSV format of Canvas' gradebook import, matching the students
+// by their SIS user ID or SIS login ID
+func writeCanvasCSV(w io.Writer, title string, grades []studentGrade) error {
+	c := csv.NewWriter(w)
+	_ = c.Write([]string{"Student", "ID", "SIS User ID", "SIS Login ID", "Section", title})
+	for _, g := range grades {
+		_ = c.Write([]string{g.Name, "", g.ID, g.Email, "", exportValue(g.Grade)})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// writeGoogleCSV writes the grades in a CSV file that Google Classroom imports, matching the students
+// by their email
+func writeGoogleCSV(w io.Writer, title string, grades []studentGrade) error {
+	c := csv.NewWriter(w)
+	_ = c.Write([]string{"Email", "Name", title})
+	for _, g := range grades {
+		_ = c.Write([]string{g.Email, g.Name, exportValue(g.Grade)})
+	}
+	c.Flush()
+	return c.Error()
+}
+
+// writeXLSX writes the grades as a spreadsheet with a row per student, in the Office Open XML format
+func writeXLSX(w io.Writer, title string, grades []studentGrade) error {
+	rows := [][]string{{"GitHub login", "ID", "Name", "Email", "Repository", title}}
+	for _, g := range grades {
+		rows = append(rows, []string{g.Login, g.ID, g.Name, g.Email, g.Repository, exportValue(g.Grade)})
+	}
+	var sheet strings.Builder
+	sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			ref := xlsxColumn(j) + strconv.Itoa(i+1)
+			if _, err := strconv.ParseFloat(value, 64); err == nil && i > 0 && j == len(row)-1 {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, ref, value)
+				continue
+			}
+			var escaped strings.Builder
+			_ = xml.EscapeText(&escaped, []byte(value))
+			fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, ref, escaped.String())
+		}
+		sheet.WriteString(`</row>`)
+	}
+	sheet.WriteString(`</sheetData></worksheet>`)
+
+	files := []struct{ name, content string }{
+		{"[Content_Types].xml", xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`},
+		{"_rels/.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Grades" sheetId="1" r:id="rId1"/></sheets></workbook>`},
+		{"xl/_rels/workbook.xml.rels", xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`},
+		{"xl/worksheets/sheet1.xml", sheet.String()},
+	}
+	z := zip.NewWriter(w)
+	for _, file := range files {
+		fw, err := z.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err = io.WriteString(fw, file.content); err != nil {
+			return err
+		}
+	}
+	return z.Close()
+}
+
+// xlsxColumn returns the letters naming the spreadsheet column with the index, starting at 0 for A
+func xlsxColumn(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
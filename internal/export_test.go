@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestXlsxColumn(t *testing.T) {
+	tests := map[int]string{
+		0:     "A",
+		1:     "B",
+		25:    "Z",
+		26:    "AA",
+		27:    "AB",
+		51:    "AZ",
+		52:    "BA",
+		701:   "ZZ",
+		702:   "AAA",
+		16383: "XFD",
+	}
+	for index, want := range tests {
+		if got := xlsxColumn(index); got != want {
+			t.Errorf("xlsxColumn(%d) = %q, want %q", index, got, want)
+		}
+	}
+}
+
+func TestExportValue(t *testing.T) {
+	tests := map[string]string{
+		"9":         "9",
+		"9/10":      "9",
+		"8.5/10":    "8.5",
+		"8,5":       "8.5",
+		"7.50":      "7.5",
+		"-1":        "-1",
+		"+2":        "2",
+		"10 points": "10",
+		"B":         "B",
+		"A+":        "A+",
+		"":          "",
+		"  8  ":     "8",
+	}
+	for grade, want := range tests {
+		if got := exportValue(grade); got != want {
+			t.Errorf("exportValue(%q) = %q, want %q", grade, got, want)
+		}
+	}
+}
+
+func TestIdentifiable(t *testing.T) {
+	tests := []struct {
+		format string
+		s      student
+		want   bool
+	}{
+		{ExportMoodle, student{ID: "2024001"}, true},
+		{ExportMoodle, student{Email: "ana@example.edu"}, true},
+		{ExportMoodle, student{Login: "ana", Name: "Ana Lima"}, false},
+		{ExportCanvas, student{ID: "2024001"}, true},
+		{ExportCanvas, student{Login: "ana"}, false},
+		{ExportGoogle, student{Email: "ana@example.edu"}, true},
+		{ExportGoogle, student{ID: "2024001", Name: "Ana Lima"}, false},
+		{ExportXLSX, student{Login: "ana"}, true},
+		{ExportXLSX, student{}, true},
+	}
+	for _, tt := range tests {
+		if got := identifiable(tt.format, tt.s); got != tt.want {
+			t.Errorf("identifiable(%s, %+v) = %v, want %v", tt.format, tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestWriteGradebookCSV(t *testing.T) {
+	grades := []studentGrade{
+		{student: student{Login: "ana", ID: "2024001", Email: "ana@example.edu", Name: "Ana Lima"}, Grade: "9/10"},
+		{student: student{Login: "bruno", ID: "2024002", Name: "Souza, Bruno"}, Grade: "B"},
+	}
+	tests := map[string]struct {
+		write func(*bytes.Buffer) error
+		want  string
+	}{
+		ExportMoodle: {func(b *bytes.Buffer) error { return writeMoodleCSV(b, "Homework", grades) },
+			"ID number,Email address,Homework\n2024001,ana@example.edu,9\n2024002,,B\n"},
+		ExportCanvas: {func(b *bytes.Buffer) error { return writeCanvasCSV(b, "Homework", grades) },
+			"Student,ID,SIS User ID,SIS Login ID,Section,Homework\nAna Lima,,2024001,ana@example.edu,,9\n\"Souza, Bruno\",,2024002,,,B\n"},
+		ExportGoogle: {func(b *bytes.Buffer) error { return writeGoogleCSV(b, "Homework", grades[:1]) },
+			"Email,Name,Homework\nana@example.edu,Ana Lima,9\n"},
+	}
+	for format, tt := range tests {
+		var b bytes.Buffer
+		if err := tt.write(&b); err != nil || b.String() != tt.want {
+			t.Errorf("%s: %q, %v, want %q", format, b.String(), err, tt.want)
+		}
+	}
+}
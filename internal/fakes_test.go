@@ -0,0 +1,262 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/github/gh-classroom/pkg/classroom"
+)
+
+// fakeClassroomAPI is an in-memory ClassroomAPI. When err is set, every call fails with it.
+type fakeClassroomAPI struct {
+	mu          sync.Mutex
+	classrooms  tui.ClassroomList
+	assignments map[string]tui.AssignmentsList
+	assignment  map[string]classroom.Assignment
+	accepted    map[string][]classroom.AcceptedAssignment
+	err         error
+	// posted records the feedback delivered to each repository, by full name
+	posted map[string][]string
+	issues map[string][]issue
+	// files are the files changed by the Feedback pull request of each repository, by full name
+	files map[string][]pullRequestFile
+	// rejectComments fails every review with inline comments, as GitHub does when the graded commit
+	// isn't the head of the Feedback pull request
+	rejectComments bool
+	// reviewed records the inline comments of the reviews posted to each repository, by full name
+	reviewed map[string][]reviewComment
+	// comments and reviews locate each posted comment and review in posted, by ID
+	comments []postedComment
+	reviews  []postedComment
+}
+
+// postedComment is the repository and the index in posted of a comment or review
+type postedComment struct {
+	fullName string
+	index    int
+}
+
+func newFakeClassroomAPI() *fakeClassroomAPI {
+	return &fakeClassroomAPI{
+		assignments: make(map[string]tui.AssignmentsList),
+		assignment:  make(map[string]classroom.Assignment),
+		accepted:    make(map[string][]classroom.AcceptedAssignment),
+		posted:      make(map[string][]string),
+		issues:      make(map[string][]issue),
+		files:       make(map[string][]pullRequestFile),
+		reviewed:    make(map[string][]reviewComment),
+	}
+}
+
+// use makes the fake the ClassroomAPI used by the commands until the test ends
+func (f *fakeClassroomAPI) use(t *testing.T) {
+	t.Helper()
+	previous := newClassroomAPI
+	newClassroomAPI = func() (ClassroomAPI, error) { return f, nil }
+	t.Cleanup(func() { newClassroomAPI = previous })
+}
+
+func (f *fakeClassroomAPI) Classrooms(int) (tui.ClassroomList, error) {
+	return f.classrooms, f.err
+}
+
+func (f *fakeClassroomAPI) Assignments(classroomId string, _ int, _ int) (tui.AssignmentsList, error) {
+	return f.assignments[classroomId], f.err
+}
+
+func (f *fakeClassroomAPI) Assignment(assignmentId string) (classroom.Assignment, error) {
+	return f.assignment[assignmentId], f.err
+}
+
+func (f *fakeClassroomAPI) AcceptedAssignments(assignmentId string, _ int, _ int) ([]classroom.AcceptedAssignment, error) {
+	return f.accepted[assignmentId], f.err
+}
+
+func (f *fakeClassroomAPI) FeedbackPullRequest(string) (int, error) {
+	return 1, f.err
+}
+
+func (f *fakeClassroomAPI) PullRequestFiles(fullName string, _ int) ([]pullRequestFile, error) {
+	return f.files[fullName], f.err
+}
+
+// PostReview rejects the whole review, as GitHub does, when one of its comments is outside the diff
+func (f *fakeClassroomAPI) PostReview(fullName string, _ int, body string, _ string, comments []reviewComment) (int64, error) {
+	if f.rejectComments && len(comments) > 0 {
+		return 0, &api.HTTPError{StatusCode: http.StatusUnprocessableEntity, Message: "Validation Failed", RequestURL: &url.URL{}}
+	}
+	for _, c := range comments {
+		commentable := false
+		for _, file := range f.files[fullName] {
+			commentable = commentable || (file.Filename == c.Path && diffLines(file.Patch)[c.Line])
+		}
+		if !commentable {
+			return 0, &api.HTTPError{StatusCode: http.StatusUnprocessableEntity, Message: "Line could not be resolved", RequestURL: &url.URL{}}
+		}
+	}
+	if err := f.post(fullName, body); err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reviewed[fullName] = append(f.reviewed[fullName], comments...)
+	f.reviews = append(f.reviews, postedComment{fullName: fullName, index: len(f.posted[fullName]) - 1})
+	return int64(len(f.reviews)), nil
+}
+
+func (f *fakeClassroomAPI) UpdateReview(fullName string, _ int, id int64, body string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id < 1 || id > int64(len(f.reviews)) || f.reviews[id-1].fullName != fullName {
+		return &api.HTTPError{StatusCode: http.StatusNotFound, Message: "Not Found", RequestURL: &url.URL{}}
+	}
+	f.posted[fullName][f.reviews[id-1].index] = body
+	return nil
+}
+
+func (f *fakeClassroomAPI) PostComment(fullName string, _ int, body string) (int64, error) {
+	if err := f.post(fullName, body); err != nil {
+		return 0, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.comments = append(f.comments, postedComment{fullName: fullName, index: len(f.posted[fullName]) - 1})
+	return int64(len(f.comments)), nil
+}
+
+func (f *fakeClassroomAPI) UpdateComment(fullName string, id int64, body string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if id < 1 || id > int64(len(f.comments)) || f.comments[id-1].fullName != fullName {
+		return &api.HTTPError{StatusCode: http.StatusNotFound, Message: "Not Found", RequestURL: &url.URL{}}
+	}
+	f.posted[fullName][f.comments[id-1].index] = body
+	return nil
+}
+
+func (f *fakeClassroomAPI) CreateIssue(fullName string, i issue) (int, error) {
+	if f.err != nil {
+		return 0, f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.issues[fullName] = append(f.issues[fullName], i)
+	return len(f.issues[fullName]), nil
+}
+
+func (f *fakeClassroomAPI) UpdateIssue(fullName string, number int, i issue) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if number < 1 || number > len(f.issues[fullName]) {
+		return &api.HTTPError{StatusCode: http.StatusNotFound, Message: "Not Found", RequestURL: &url.URL{}}
+	}
+	f.issues[fullName][number-1] = i
+	return nil
+}
+
+func (f *fakeClassroomAPI) post(fullName string, body string) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.posted[fullName] = append(f.posted[fullName], body)
+	return nil
+}
+
+// fakeGitRunner is an in-memory GitRunner. It records the git commands it is asked to run and answers
+// each one with the outcome registered for the longest prefix of its arguments, or with success and no
+// output if none was registered.
+type fakeGitRunner struct {
+	mu       sync.Mutex
+	outcomes map[string]fakeGitOutcome
+	calls    []string
+}
+
+type fakeGitOutcome struct {
+	stdout string
+	stderr string
+	failed bool
+}
+
+func newFakeGitRunner() *fakeGitRunner {
+	return &fakeGitRunner{outcomes: make(map[string]fakeGitOutcome)}
+}
+
+// use makes the fake the GitRunner used by the commands until the test ends
+func (f *fakeGitRunner) use(t *testing.T) {
+	t.Helper()
+	previous := gitRunner
+	gitRunner = f
+	t.Cleanup(func() { gitRunner = previous })
+}
+
+// on registers the outcome of the git commands whose arguments start with the given ones
+func (f *fakeGitRunner) on(args string, outcome fakeGitOutcome) {
+	f.outcomes[args] = outcome
+}
+
+func (f *fakeGitRunner) outcome(args []string) fakeGitOutcome {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	command := strings.Join(args, " ")
+	f.calls = append(f.calls, command)
+	var match string
+	for prefix := range f.outcomes {
+		if strings.HasPrefix(command, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+	return f.outcomes[match]
+}
+
+func (f *fakeGitRunner) Output(_ string, args ...string) ([]byte, error) {
+	o := f.outcome(args)
+	if o.failed {
+		return []byte(o.stdout), fmt.Errorf("git %s: exit status 1", strings.Join(args, " "))
+	}
+	return []byte(o.stdout), nil
+}
+
+// Command returns a process that runs TestFakeGitProcess, which prints the registered output and exits
+// with the registered status, as a step's process must be a real process
+func (f *fakeGitRunner) Command(directory string, args ...string) *exec.Cmd {
+	o := f.outcome(args)
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFakeGitProcess$")
+	cmd.Dir = directory
+	cmd.Env = append(os.Environ(), "CLARO_FAKE_GIT=1", "CLARO_FAKE_GIT_STDOUT="+o.stdout, "CLARO_FAKE_GIT_STDERR="+o.stderr)
+	if o.failed {
+		cmd.Env = append(cmd.Env, "CLARO_FAKE_GIT_FAIL=1")
+	}
+	return cmd
+}
+
+// TestFakeGitProcess is the process returned by fakeGitRunner.Command. It does nothing when run as a test.
+func TestFakeGitProcess(t *testing.T) {
+	if os.Getenv("CLARO_FAKE_GIT") != "1" {
+		return
+	}
+	_, _ = fmt.Fprint(os.Stdout, os.Getenv("CLARO_FAKE_GIT_STDOUT"))
+	_, _ = fmt.Fprint(os.Stderr, os.Getenv("CLARO_FAKE_GIT_STDERR"))
+	if os.Getenv("CLARO_FAKE_GIT_FAIL") == "1" {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
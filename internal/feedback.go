@@ -0,0 +1,207 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"strconv"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+var (
+	githubRepositoryPattern = regexp.MustCompile(`github\.com[:/]([^/]+/[^/]+?)(\.git)?/?$`)
+	pullRequestPattern      = regexp.MustCompile(`/pull/(\d+)$`)
+)
+
+// repositoryFullName returns the "owner/repository" name of a student's repository, as recorded
+// in the manifest or, for directories cloned by older versions of claro, from its origin remote
+func repositoryFullName(directory string, entry manifestRepository) (string, error) {
+	if entry.FullName != "" {
+		return entry.FullName, nil
+	}
+	url, err := gitBackend.RemoteURL(directory)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", i18n.T("feedback.originUnreadable"), err)
+	}
+	if match := githubRepositoryPattern.FindStringSubmatch(url); match != nil {
+		return match[1], nil
+	}
+	return "", errors.New(i18n.T("feedback.notGitHub", url))
+}
+
+// restPostFeedback delivers the grade file through the GitHub REST API, according to the configured
+// delivery mode: as a review or a comment on the "Feedback" pull request, or as an issue. With the
+// gradetag setting, the graded commit is tagged and the tag pushed before the feedback is delivered.
+func restPostFeedback(directory string, submission pair) step {
+	result := repositoryResult(actionPush, directory)
+	mode := viper.GetString("delivery")
+	if mode != deliveryReview && mode != deliveryComment && mode != deliveryIssue {
+		return doneStep(result.failed(i18n.T("feedback.unknownDelivery", mode)))
+	}
+	parentDir := filepath.Dir(directory)
+	gradeFile := filepath.Join(parentDir, submission.gradeFilename.Name())
+	f, err := readFeedback(directory, gradeFile)
+	if err != nil {
+		return doneStep(result.failed(err.Error()))
+	}
+	var push gitOperation
+	if viper.GetBool("gradetag") {
+		message, err := commitMessage(newCommitMessageData(directory, gradeFile, result, f))
+		if err != nil {
+			return doneStep(result.failed(err.Error()))
+		}
+		tag := gradeTagName(parentDir)
+		if err = tagGradedCommit(directory, tag, f.gradedCommit, message); err != nil {
+			return doneStep(result.failed(i18n.T("git.tagFailed", tag, err)))
+		}
+		push = gitBackend.PushTags(directory, tag)
+	}
+	return newStep(push, func(err error) Result {
+		if err != nil {
+			return result.failedWith(err)
+		}
+		m, _ := loadManifest(parentDir)
+		entry, _ := m.repository(result.Repository)
+		fullName, err := repositoryFullName(directory, entry)
+		if err != nil {
+			return result.failed(err.Error())
+		}
+		client, err := newClassroomAPI()
+		if err != nil {
+			return result.failed(err.Error())
+		}
+		if mode == deliveryIssue {
+			return postFeedbackIssue(client, parentDir, m, result, fullName, f)
+		}
+		var number int
+		if match := pullRequestPattern.FindStringSubmatch(entry.Feedback); match != nil {
+			number, _ = strconv.Atoi(match[1])
+		} else if number, err = client.FeedbackPullRequest(fullName); err != nil {
+			return result.failedWith(restError(err, i18n.T("feedback.findPRFailed")))
+		}
+		if mode == deliveryComment {
+			return postFeedbackComment(client, parentDir, m, result, fullName, number, f)
+		}
+		return postReview(client, parentDir, m, result, fullName, number, f)
+	})
+}
+
+// postReview posts the feedback as a review of the Feedback pull request. Annotations on the lines of
+// the pull request's diff become inline comments on the graded commit, and the others are listed in the
+// review's body. If GitHub still rejects the inline comments, such as when the graded commit is not the
+// pull request's head, the review is posted again with every annotation listed in its body. The review's
+// ID is recorded in the manifest, and a later push updates the review's body instead. Inline comments
+// can't be edited, so every annotation is then listed in the body.
+func postReview(client ClassroomAPI, submissionsDirectory string, m manifest, result Result, fullName string, number int, f feedback) Result {
+	entry, _ := m.repository(result.Repository)
+	action := "feedback.reviewUpdated"
+	id := entry.Review
+	var err error
+	if id != 0 {
+		err = client.UpdateReview(fullName, number, id, f.annotatedListing())
+	}
+	// The review of the previous push may have been deleted, or posted on another pull request
+	if id == 0 || hasStatus(err, http.StatusNotFound) {
+		action = "feedback.reviewPosted"
+		id, err = postNewReview(client, fullName, number, f)
+	}
+	if err != nil {
+		return result.failedWith(restError(err, i18n.T("feedback.postFailed", number)))
+	}
+	if entry.Review != id {
+		entry.Name, entry.FullName, entry.Review = result.Repository, fullName, id
+		m = m.withRepository(entry)
+		if e := saveManifest(submissionsDirectory, m); e != nil {
+			return result.failed(i18n.T("feedback.reviewManifest", number, e))
+		}
+	}
+	return result.succeeded(i18n.T(action, number))
+}
+
+// postNewReview posts a new review of the Feedback pull request and returns its ID
+func postNewReview(client ClassroomAPI, fullName string, number int, f feedback) (int64, error) {
+	commentable := make(map[string]map[int]bool)
+	if len(f.annotations) > 0 {
+		// Without the diff, every annotation is listed in the body
+		files, _ := client.PullRequestFiles(fullName, number)
+		for _, file := range files {
+			commentable[file.Filename] = diffLines(file.Patch)
+		}
+	}
+	body, comments := f.review(commentable)
+	id, err := client.PostReview(fullName, number, body, f.commit, comments)
+	if len(comments) > 0 && hasStatus(err, http.StatusUnprocessableEntity) {
+		id, err = client.PostReview(fullName, number, f.annotatedListing(), f.commit, nil)
+	}
+	return id, err
+}
+
+// postFeedbackComment posts the feedback as a comment on the Feedback pull request, or updates the
+// comment posted by a previous push. The comment's ID is recorded in the manifest.
+func postFeedbackComment(client ClassroomAPI, submissionsDirectory string, m manifest, result Result, fullName string, number int, f feedback) Result {
+	entry, _ := m.repository(result.Repository)
+	body := f.annotatedListing()
+	action := "feedback.commentUpdated"
+	id := entry.Comment
+	var err error
+	if id != 0 {
+		err = client.UpdateComment(fullName, id, body)
+	}
+	// The comment of the previous push may have been deleted
+	if id == 0 || hasStatus(err, http.StatusNotFound) {
+		action = "feedback.commentPosted"
+		id, err = client.PostComment(fullName, number, body)
+	}
+	if err != nil {
+		return result.failedWith(restError(err, i18n.T("feedback.postFailed", number)))
+	}
+	if entry.Comment != id {
+		entry.Name, entry.FullName, entry.Comment = result.Repository, fullName, id
+		m = m.withRepository(entry)
+		if e := saveManifest(submissionsDirectory, m); e != nil {
+			return result.failed(i18n.T("feedback.commentManifest", number, e))
+		}
+	}
+	return result.succeeded(i18n.T(action, number))
+}
+
+// postFeedbackIssue creates the feedback issue in the student's repository, or updates the one created
+// by a previous push, unless it no longer exists. The issue number is recorded in the manifest.
+func postFeedbackIssue(client ClassroomAPI, submissionsDirectory string, m manifest, result Result, fullName string, f feedback) Result {
+	entry, _ := m.repository(result.Repository)
+	i := issue{Title: viper.GetString("title"), Body: f.annotatedListing()}
+	if label := viper.GetString("label"); label != "" {
+		i.Labels = []string{label}
+	}
+	var err error
+	action := "feedback.issueUpdated"
+	number := entry.Issue
+	if number != 0 {
+		err = client.UpdateIssue(fullName, number, i)
+	}
+	// The issue of the previous push may have been deleted or transferred
+	if number == 0 || hasStatus(err, http.StatusNotFound) || hasStatus(err, http.StatusGone) {
+		action = "feedback.issueCreated"
+		number, err = client.CreateIssue(fullName, i)
+	}
+	if err != nil {
+		return result.failedWith(restError(err, i18n.T("feedback.issueFailed")))
+	}
+	if entry.Issue != number {
+		entry.Name, entry.FullName, entry.Issue = result.Repository, fullName, number
+		m = m.withRepository(entry)
+		if e := saveManifest(submissionsDirectory, m); e != nil {
+			return result.failed(i18n.T("feedback.issueManifest", number, e))
+		}
+	}
+	return result.succeeded(i18n.T(action, number))
+}
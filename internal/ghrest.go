@@ -6,51 +6,78 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/emersonmello/claro/internal/tui"
 	"github.com/github/gh-classroom/pkg/classroom"
+	"github.com/spf13/viper"
 )
 
+// ClassroomAPI is the part of the GitHub REST API used by claro: the GitHub Classroom endpoints that list
+// the classrooms, assignments and accepted assignments, and the endpoints that deliver the feedback
+type ClassroomAPI interface {
+	Classrooms(page int) (tui.ClassroomList, error)
+	Assignments(classroomId string, page int, perPage int) (tui.AssignmentsList, error)
+	Assignment(assignmentId string) (classroom.Assignment, error)
+	AcceptedAssignments(assignmentId string, page int, perPage int) ([]classroom.AcceptedAssignment, error)
+	// FeedbackPullRequest returns the number of the "Feedback" pull request that GitHub Classroom
+	// opens in the student's repository
+	FeedbackPullRequest(fullName string) (int, error)
+	// PullRequestFiles returns the files changed by a pull request, with their patch
+	PullRequestFiles(fullName string, number int) ([]pullRequestFile, error)
+	// PostReview posts a review, with optional inline comments, on a pull request and returns its ID
+	PostReview(fullName string, number int, body string, commitId string, comments []reviewComment) (int64, error)
+	// UpdateReview replaces the body of a review on a pull request. Its inline comments are kept.
+	UpdateReview(fullName string, number int, id int64, body string) error
+	// PostComment posts a comment on an issue or pull request and returns its ID
+	PostComment(fullName string, number int, body string) (int64, error)
+	// UpdateComment replaces the body of a comment on an issue or pull request
+	UpdateComment(fullName string, id int64, body string) error
+	// CreateIssue creates an issue and returns its number
+	CreateIssue(fullName string, i issue) (int, error)
+	// UpdateIssue replaces the title, body and labels of an issue
+	UpdateIssue(fullName string, number int, i issue) error
+}
+
+// newClassroomAPI returns the client of the GitHub REST API. Tests replace it with a fake.
+var newClassroomAPI = func() (ClassroomAPI, error) {
+	client, er := getAPIRESTClient()
+	if client == nil {
+		return nil, errors.New(string(er))
+	}
+	return restClassroomAPI{client: client}, nil
+}
+
 func restGetClassrooms(page int) tea.Cmd {
 	return func() tea.Msg {
-		client, er := getAPIRESTClient()
-		if client == nil {
-			return er
+		client, err := newClassroomAPI()
+		if err != nil {
+			return tui.ErrorMsg(err.Error())
 		}
-		var classroomList tui.ClassroomList
-		var path = "classrooms"
-		if page != 0 {
-			path = fmt.Sprintf("%s?page=%d", path, page)
+		classroomList, e := client.Classrooms(page)
+		if e != nil {
+			return errorMsg(restError(e, i18n.T("rest.classroomsFailed")))
 		}
-		if e := client.Get(path, &classroomList); e != nil {
-			return checkIfBadCredentialError(e, "Failed to retrieve the classrooms list")
-		}
-
 		return classroomList
 		//return generateRandomClassrooms(30)
 	}
 }
 func restGetAssignments(classroomId string, page int, perPage int) tea.Cmd {
 	return func() tea.Msg {
-		client, er := getAPIRESTClient()
-		if client == nil {
-			return er
-		}
-		var assignments tui.AssignmentsList
-		var path = fmt.Sprintf("classrooms/%s/assignments", classroomId)
-		if page != 0 {
-			path += fmt.Sprintf("?page=%v", page)
+		client, err := newClassroomAPI()
+		if err != nil {
+			return tui.ErrorMsg(err.Error())
 		}
-		if perPage != 0 {
-			path += fmt.Sprintf("&per_page=%v", perPage)
-		}
-		if e := client.Get(path, &assignments); e != nil {
-			return checkIfBadCredentialError(e, "Failed to retrieve the assignments list")
+		assignments, e := client.Assignments(classroomId, page, perPage)
+		if e != nil {
+			return errorMsg(restError(e, i18n.T("rest.assignmentsFailed")))
 		}
 		return assignments
 		//return generateRandomAssignments(30)
@@ -59,23 +86,57 @@ func restGetAssignments(classroomId string, page int, perPage int) tea.Cmd {
 
 func restGetAcceptedAssignmentsList(assignmentId string, page int, perPage int) tea.Cmd {
 	return func() tea.Msg {
-		client, er := getAPIRESTClient()
-		if client == nil {
-			return er
-		}
-		var repos = make([]classroom.AcceptedAssignment, 0)
-		var path = fmt.Sprintf("assignments/%v/accepted_assignments", assignmentId)
-		if page != 0 {
-			path += fmt.Sprintf("?page=%v", page)
+		repos, err := fetchAcceptedAssignments(assignmentId, page, perPage)
+		if err != nil {
+			return errorMsg(err)
 		}
-		if perPage != 0 {
-			path += fmt.Sprintf("&per_page=%v", perPage)
+		return repos
+	}
+}
+
+// restGetAssignmentSubmissions returns a tea.Cmd that retrieves an assignment and its accepted assignments
+func restGetAssignmentSubmissions(assignmentId string) tea.Cmd {
+	return func() tea.Msg {
+		assignment, err := fetchAssignment(assignmentId)
+		if err != nil {
+			return errorMsg(err)
 		}
-		if e := client.Get(path, &repos); e != nil {
-			return checkIfBadCredentialError(e, "Failed to retrieve the accepted assignments list")
+		repos, err := fetchAcceptedAssignments(assignmentId, 0, 0)
+		if err != nil {
+			return errorMsg(err)
 		}
-		return repos
+		return assignmentSubmissions{assignment: assignment, accepted: repos}
+	}
+}
+
+// assignmentSubmissions is an assignment and its accepted assignments
+type assignmentSubmissions struct {
+	assignment classroom.Assignment
+	accepted   []classroom.AcceptedAssignment
+}
+
+func fetchAssignment(assignmentId string) (classroom.Assignment, error) {
+	client, err := newClassroomAPI()
+	if err != nil {
+		return classroom.Assignment{}, err
+	}
+	assignment, e := client.Assignment(assignmentId)
+	if e != nil {
+		return assignment, restError(e, i18n.T("rest.assignmentFailed"))
 	}
+	return assignment, nil
+}
+
+func fetchAcceptedAssignments(assignmentId string, page int, perPage int) ([]classroom.AcceptedAssignment, error) {
+	client, err := newClassroomAPI()
+	if err != nil {
+		return nil, err
+	}
+	repos, e := client.AcceptedAssignments(assignmentId, page, perPage)
+	if e != nil {
+		return nil, restError(e, i18n.T("rest.acceptedFailed"))
+	}
+	return repos, nil
 }
 
 func getAPIRESTClient() (*api.RESTClient, tui.ErrorMsg) {
@@ -86,25 +147,224 @@ func getAPIRESTClient() (*api.RESTClient, tui.ErrorMsg) {
 	//	client, err = api.DefaultRESTClient()
 	//} else {
 	// Ok, no problem. Since I'm not using GitHub CLI, I need to have access to a Personal Access Token
-	opts := api.ClientOptions{AuthToken: tui.UserGitHubPAT}
+	opts := api.ClientOptions{AuthToken: tui.UserGitHubPAT, Host: viper.GetString("host"), Transport: newRetryTransport(http.DefaultTransport)}
 	client, err = api.NewRESTClient(opts)
 	//}
 	var errorMsg tui.ErrorMsg
 	if client == nil {
-		errorMsg = tui.ErrorMsg(fmt.Sprintf("An error occurred while retrieving the GitHub REST API client. %s", err))
+		errorMsg = tui.ErrorMsg(i18n.T("rest.clientError", err))
 	}
 	return client, errorMsg
 }
 
-func checkIfBadCredentialError(e error, msg string) tui.ErrorMsg {
+// authError is returned when GitHub rejects the user's credentials
+type authError string
+
+func (e authError) Error() string {
+	return string(e)
+}
+
+// isAuthError reports whether the error, or any error it wraps, is an authentication failure
+func isAuthError(err error) bool {
+	var a authError
+	var g *gitError
+	return errors.As(err, &a) || errors.As(err, &g) && g.reason == gitAuthFailed
+}
+
+// restError returns the error of a failed GitHub REST API request. If the credentials were rejected,
+// it explains how to replace the GitHub Personal Access Token.
+func restError(e error, msg string) error {
 	var hE *api.HTTPError
 	s := "claro config"
 	if errors.As(e, &hE) {
 		if hE.StatusCode == http.StatusUnauthorized {
-			return tui.ErrorMsg(fmt.Sprintf("%s => HTTP %d: %s."+
-				"\nVisit https://docs.github.com/en/authentication/keeping-your-account-and-data-secure/managing-your-personal-access-tokens to generate a new PAT"+
-				"\nThen, execute '%s' to update your GitHub Personal Access Token.", msg, hE.StatusCode, hE.Message, s))
+			return authError(i18n.T("rest.badCredentials", msg, hE.StatusCode, hE.Message, s))
+		}
+	}
+	return fmt.Errorf("%s. %w", msg, e)
+}
+
+// errorMsg returns the message the models print before quitting because of the error
+func errorMsg(err error) tea.Msg {
+	if isAuthError(err) {
+		return tui.AuthErrorMsg(err.Error())
+	}
+	return tui.ErrorMsg(err.Error())
+}
+
+// pullRequest represents the fields of a GitHub pull request used by claro
+type pullRequest struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	Base   struct {
+		Ref string `json:"ref"`
+	} `json:"base"`
+}
+
+// pullRequestFile represents a file changed by a pull request. Patch is its diff, in the unified format.
+type pullRequestFile struct {
+	Filename string `json:"filename"`
+	Patch    string `json:"patch"`
+}
+
+// reviewComment represents an inline comment of a pull request review
+type reviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Body string `json:"body"`
+}
+
+// restClassroomAPI is the ClassroomAPI backed by the GitHub REST API
+type restClassroomAPI struct {
+	client *api.RESTClient
+}
+
+func (c restClassroomAPI) Classrooms(page int) (tui.ClassroomList, error) {
+	var classroomList tui.ClassroomList
+	var path = "classrooms"
+	if page != 0 {
+		path = fmt.Sprintf("%s?page=%d", path, page)
+	}
+	err := c.client.Get(path, &classroomList)
+	return classroomList, err
+}
+
+func (c restClassroomAPI) Assignments(classroomId string, page int, perPage int) (tui.AssignmentsList, error) {
+	var assignments tui.AssignmentsList
+	var path = fmt.Sprintf("classrooms/%s/assignments", classroomId)
+	if page != 0 {
+		path += fmt.Sprintf("?page=%v", page)
+	}
+	if perPage != 0 {
+		path += fmt.Sprintf("&per_page=%v", perPage)
+	}
+	err := c.client.Get(path, &assignments)
+	return assignments, err
+}
+
+func (c restClassroomAPI) Assignment(assignmentId string) (classroom.Assignment, error) {
+	var assignment classroom.Assignment
+	err := c.client.Get(fmt.Sprintf("assignments/%s", assignmentId), &assignment)
+	return assignment, err
+}
+
+func (c restClassroomAPI) AcceptedAssignments(assignmentId string, page int, perPage int) ([]classroom.AcceptedAssignment, error) {
+	var repos = make([]classroom.AcceptedAssignment, 0)
+	var path = fmt.Sprintf("assignments/%v/accepted_assignments", assignmentId)
+	if page != 0 {
+		path += fmt.Sprintf("?page=%v", page)
+	}
+	if perPage != 0 {
+		path += fmt.Sprintf("&per_page=%v", perPage)
+	}
+	err := c.client.Get(path, &repos)
+	return repos, err
+}
+
+func (c restClassroomAPI) FeedbackPullRequest(fullName string) (int, error) {
+	var pulls []pullRequest
+	if e := c.client.Get(fmt.Sprintf("repos/%s/pulls?state=open&per_page=100", fullName), &pulls); e != nil {
+		return 0, e
+	}
+	for _, p := range pulls {
+		if p.Base.Ref == "feedback" || p.Title == "Feedback" {
+			return p.Number, nil
 		}
 	}
-	return tui.ErrorMsg(fmt.Sprintf("%s. %s", msg, e))
+	return 0, errors.New(i18n.T("feedback.noPullRequest"))
+}
+
+func (c restClassroomAPI) PullRequestFiles(fullName string, number int) ([]pullRequestFile, error) {
+	var files []pullRequestFile
+	// GitHub lists at most 3000 files, 100 per page
+	for page := 1; page <= 30; page++ {
+		var pageFiles []pullRequestFile
+		if e := c.client.Get(fmt.Sprintf("repos/%s/pulls/%d/files?per_page=100&page=%d", fullName, number, page), &pageFiles); e != nil {
+			return nil, e
+		}
+		files = append(files, pageFiles...)
+		if len(pageFiles) < 100 {
+			break
+		}
+	}
+	return files, nil
+}
+
+func (c restClassroomAPI) PostReview(fullName string, number int, body string, commitId string, comments []reviewComment) (int64, error) {
+	review := struct {
+		CommitId string          `json:"commit_id,omitempty"`
+		Body     string          `json:"body"`
+		Event    string          `json:"event"`
+		Comments []reviewComment `json:"comments,omitempty"`
+	}{CommitId: commitId, Body: body, Event: "COMMENT", Comments: comments}
+	payload, err := json.Marshal(review)
+	if err != nil {
+		return 0, err
+	}
+	var created struct {
+		Id int64 `json:"id"`
+	}
+	err = c.client.Post(fmt.Sprintf("repos/%s/pulls/%d/reviews", fullName, number), bytes.NewReader(payload), &created)
+	return created.Id, err
+}
+
+func (c restClassroomAPI) UpdateReview(fullName string, number int, id int64, body string) error {
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+	return c.client.Put(fmt.Sprintf("repos/%s/pulls/%d/reviews/%d", fullName, number, id), bytes.NewReader(payload), nil)
+}
+
+func (c restClassroomAPI) PostComment(fullName string, number int, body string) (int64, error) {
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return 0, err
+	}
+	var created struct {
+		Id int64 `json:"id"`
+	}
+	err = c.client.Post(fmt.Sprintf("repos/%s/issues/%d/comments", fullName, number), bytes.NewReader(payload), &created)
+	return created.Id, err
+}
+
+func (c restClassroomAPI) UpdateComment(fullName string, id int64, body string) error {
+	payload, err := json.Marshal(map[string]string{"body": body})
+	if err != nil {
+		return err
+	}
+	return c.client.Patch(fmt.Sprintf("repos/%s/issues/comments/%d", fullName, id), bytes.NewReader(payload), nil)
+}
+
+// hasStatus reports whether the GitHub REST API answered the request with the status code
+func hasStatus(err error, status int) bool {
+	var hE *api.HTTPError
+	return errors.As(err, &hE) && hE.StatusCode == status
+}
+
+// issue represents the fields of a GitHub issue written by claro
+type issue struct {
+	Title  string   `json:"title"`
+	Body   string   `json:"body"`
+	Labels []string `json:"labels,omitempty"`
+}
+
+func (c restClassroomAPI) CreateIssue(fullName string, i issue) (int, error) {
+	payload, err := json.Marshal(i)
+	if err != nil {
+		return 0, err
+	}
+	var created struct {
+		Number int `json:"number"`
+	}
+	err = c.client.Post(fmt.Sprintf("repos/%s/issues", fullName), bytes.NewReader(payload), &created)
+	return created.Number, err
+}
+
+func (c restClassroomAPI) UpdateIssue(fullName string, number int, i issue) error {
+	payload, err := json.Marshal(i)
+	if err != nil {
+		return err
+	}
+	return c.client.Patch(fmt.Sprintf("repos/%s/issues/%d", fullName, number), bytes.NewReader(payload), nil)
 }
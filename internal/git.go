@@ -6,157 +6,239 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
-	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
-	"github.com/emersonmello/claro/internal/tui"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/github/gh-classroom/pkg/classroom"
 	"github.com/spf13/viper"
 )
 
-func gitCloneAssignment(assignment classroom.AcceptedAssignment) tea.Cmd {
-	var directory string
+// submissionsDirectory returns the directory where the repositories of an assignment are cloned
+func submissionsDirectory(assignment classroom.Assignment) string {
+	directory := viper.GetString("cloneroot")
 
 	if strings.HasPrefix(directory, "~") {
 		dirname, _ := os.UserHomeDir()
 		directory = filepath.Join(dirname, directory[1:])
 	}
 
-	fullPath, _ := filepath.Abs(filepath.Join(directory, assignment.Assignment.Slug+"-submissions"))
+	fullPath, _ := filepath.Abs(filepath.Join(directory, assignment.Slug+"-submissions"))
+	return fullPath
+}
+
+// gitCloneAssignment clones the repository of an accepted assignment and creates its grade file. An
+// existing clone is skipped, unless the journal records it as interrupted, in which case it is removed
+// and cloned again. started is called when the clone starts, so an existing clone that is skipped is
+// never taken for an interrupted one.
+func gitCloneAssignment(assignment classroom.AcceptedAssignment, interrupted bool, started func()) step {
+	logins := studentLogins(assignment)
+	fullPath := submissionsDirectory(assignment.Assignment)
+	result := Result{Repository: assignment.Repository.Name, Student: strings.Join(logins, ","), Name: rosterNames(fullPath, logins), Action: actionClone}
 
 	if _, err := os.Stat(fullPath); os.IsNotExist(err) {
 		err = os.MkdirAll(fullPath, 0755)
 		if err != nil {
-			return func() tea.Msg {
-				return tui.ErrorMsg(fmt.Sprintf("Error creating directory: %s", fullPath))
-			}
+			return doneStep(result.failed(i18n.T("git.mkdirError", fullPath)))
 		}
 	}
 	clonePath := filepath.Join(fullPath, assignment.Repository.Name)
-	if _, err := os.Stat(clonePath); os.IsNotExist(err) {
-		cmd := exec.Command("git", "clone", "-q", assignment.Repository.HtmlUrl, clonePath)
-		return tea.ExecProcess(cmd, func(err error) tea.Msg {
-			if err != nil {
-				return tui.ErrorMsg(fmt.Sprintf("Error '%s' encountered while cloning: %s", err, assignment.Repository.FullName))
+	detail := ""
+	if _, err := os.Stat(clonePath); !os.IsNotExist(err) {
+		// Only the journal tells an interrupted clone apart: a complete clone of an empty repository has no
+		// HEAD commit either
+		if !interrupted {
+			return doneStep(result.skipped(i18n.T("git.alreadyExists")))
+		}
+		if err = os.RemoveAll(clonePath); err != nil {
+			return doneStep(result.failed(i18n.T("git.repairError", err)))
+		}
+		detail = i18n.T("git.repaired")
+	}
+	started()
+	return newStep(gitBackend.Clone(assignment.Repository.HtmlUrl, clonePath), func(err error) Result {
+		if err != nil {
+			return result.failed(i18n.T("git.cloneError", err, assignment.Repository.FullName))
+		}
+		// Getting the commit hash and date to be used in the grade file
+		commit, commitDate, _ := gitBackend.Head(clonePath)
+		commitStr := fmt.Sprintf("> Commit: %s | %s", commit, commitDate)
+		if students := rosterStudents(fullPath, logins); len(students) > 0 {
+			commitStr += "\n> " + i18n.T("defaults.student") + ": " + studentIdentities(students)
+		}
+		// Creating grade file .md
+		gradeFileName := filepath.Join(fullPath, "grade-"+assignment.Repository.Name+".md")
+		if zeroGradePlaceholder(gradeFileName) {
+			if e := os.Remove(gradeFileName); e != nil {
+				return result.failed(i18n.T("git.gradeFileCreate", e))
 			}
-			// Getting the commit hash to be used in the grade file
-			cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
-			commit, _ := executeCommand(cmd, clonePath)
-			// Getting commit date
-			cmd = exec.Command("git", "show", "-s", "--format=%ci")
-			commitDate, _ := executeCommand(cmd, clonePath)
-			commitStr := fmt.Sprintf("> Commit: %s | %s", strings.ReplaceAll(string(commit), "\n", ""), strings.ReplaceAll(string(commitDate), "\n", ""))
-			// Creating grade file .md
-			gradeFileName := filepath.Join(fullPath, "grade-"+assignment.Repository.Name+".md")
-			if _, err = os.Stat(gradeFileName); os.IsNotExist(err) {
-				if f, e := os.Create(gradeFileName); e != nil {
-					return tui.ErrorMsg(fmt.Sprintf("Unable to create grade file: %s", e))
-				} else {
-					mdText := fmt.Sprintf("# %s\n%s\n\n- ...\n- **%s** \n\n", viper.GetString("title"), commitStr, viper.GetString("grade"))
-					if _, e = f.WriteString(mdText); e != nil {
-						return tui.ErrorMsg(fmt.Sprintf("Unable to write to markdown file: %s", e))
-					}
-					defer func(f *os.File) {
-						_ = f.Close()
-					}(f)
+			detail = strings.TrimPrefix(detail+", "+i18n.T("git.zeroGradeReplaced"), ", ")
+		}
+		if _, err = os.Stat(gradeFileName); os.IsNotExist(err) {
+			rubricText := "- ...\n"
+			if r := viper.GetString("rubric"); r != "" {
+				content, e := os.ReadFile(expandHomeDirectory(r))
+				if e != nil {
+					return result.failed(i18n.T("git.rubricError", e))
 				}
+				rubricText = strings.TrimRight(string(content), "\n") + "\n"
 			}
-			return tui.SuccessfullMsg(assignment.Repository.Name)
-		})
-	}
-	return func() tea.Msg {
-		return tui.ErrorMsg("Repository already exists, skipping clone")
-	}
+			if f, e := os.Create(gradeFileName); e != nil {
+				return result.failed(i18n.T("git.gradeFileCreate", e))
+			} else {
+				mdText := fmt.Sprintf("# %s\n%s\n\n%s- **%s** \n\n", viper.GetString("title"), commitStr, rubricText, viper.GetString("grade"))
+				if groupAssignment(assignment) {
+					mdText += membersSection(logins)
+				}
+				if _, e = f.WriteString(mdText); e != nil {
+					return result.failed(i18n.T("git.gradeFileWrite", e))
+				}
+				defer func(f *os.File) {
+					_ = f.Close()
+				}(f)
+			}
+		}
+		return result.succeeded(detail)
+	})
 }
 
-func gitPullCmd(directory string, repositoryName string) tea.Cmd {
+func gitPull(directory string) step {
 	//pause := time.Duration(rand.Int63n(1000)+3000) * time.Millisecond
 	//time.Sleep(pause)
-
-	cmd := exec.Command("git", "reset", "-q")
-	if _, e := executeCommand(cmd, directory); e != nil {
-		return func() tea.Msg { return tui.ErrorMsg("This is not a git repository") }
-	}
-	cmd = exec.Command("git", "stash", "-a", "-q")
-	_, _ = executeCommand(cmd, directory)
-	cmd = exec.Command("git", "rev-list", "--all", "--count")
-	totalCommitsBeforePull, _ := executeCommand(cmd, directory)
-	cmd = exec.Command("git", "pull", "-q", "--rebase")
-	cmd.Dir = directory
-	return tea.ExecProcess(cmd, func(err error) tea.Msg {
-		cmd = exec.Command("git", "rev-list", "--all", "--count")
-		totalCommitsAfterPull, _ := executeCommand(cmd, directory)
-		cmd = exec.Command("git", "stash", "pop")
-		_, _ = executeCommand(cmd, directory)
+	result := repositoryResult(actionPull, directory)
+	headBeforePull, _, _ := gitBackend.Head(directory)
+	return newStep(gitBackend.Pull(directory), func(err error) Result {
 		if err != nil {
-			return tui.ErrorMsg("Failed to execute 'git pull'")
+			return result.failedWith(err)
 		}
-		if !bytes.Equal(totalCommitsAfterPull, totalCommitsBeforePull) {
-			str := lipgloss.NewStyle().Foreground(lipgloss.Color("#E9E64D")).Italic(true).SetString("new commits").Render()
-			return tui.SuccessfullPullMsg(fmt.Sprintf("%s %s", repositoryName, str))
+		if headAfterPull, _, _ := gitBackend.Head(directory); headAfterPull != headBeforePull {
+			return result.succeeded(i18n.T("git.newCommits"))
 		}
-		return tui.SuccessfullPullMsg(repositoryName)
+		return result.succeeded("")
 	})
 }
 
-func gitCommitAndPushCmd(directory string, repositoryName string, submission pair) tea.Cmd {
+// gitDeliverFeedback returns the step that delivers the grade file of a repository, according to the
+// configured feedback delivery mode
+func gitDeliverFeedback(directory string, submission pair) step {
+	if DeliveryUsesAPI() {
+		return restPostFeedback(directory, submission)
+	}
+	return gitCommitAndPush(directory, submission)
+}
+
+func gitCommitAndPush(directory string, submission pair) step {
+	result := repositoryResult(actionPush, directory)
 	gradeFileName := viper.GetString("filename")
 	parentDir := filepath.Dir(directory)
 	srcName, _ := filepath.Abs(filepath.Join(parentDir, submission.gradeFilename.Name()))
 	dstName, _ := filepath.Abs(filepath.Join(directory, gradeFileName))
-	cmd := exec.Command("git", "reset", "-q")
-	_, _ = executeCommand(cmd, directory)
-	cmd = exec.Command("git", "stash", "-a", "-q")
-	_, _ = executeCommand(cmd, directory)
-	if errCopy := copyFile(srcName, dstName); errCopy == nil {
-		cmd := exec.Command("git", "add", gradeFileName)
-		_, _ = executeCommand(cmd, directory)
-		cmd = exec.Command("git", "status", "--porcelain")
-		o, _ := executeCommand(cmd, directory)
-		cmd = exec.Command("git", "stash", "pop")
-		_, _ = executeCommand(cmd, directory)
-		var str string
-		if string(o) == "" {
-			str = lipgloss.NewStyle().Foreground(lipgloss.Color("#E9E64D")).Italic(true).SetString("nothing to commit, working tree clean").Render()
-		} else {
-			cmd = exec.Command("git", "commit", "-q", "-m", viper.GetString("message"))
-			_, _ = executeCommand(cmd, directory)
-		}
-		cmd = exec.Command("git", "push", "-q")
-		cmd.Dir = directory
-		return tea.ExecProcess(cmd, func(err error) tea.Msg {
-			if err != nil {
-				return tui.ErrorMsg(fmt.Sprintf("Failed to execute 'git push' for %s", repositoryName))
-			}
-			return tui.SuccessfullMsg(fmt.Sprintf("%s %s", repositoryName, str))
-		})
+	f, errFeedback := readFeedback(directory, srcName)
+	if errFeedback != nil {
+		return doneStep(result.failed(errFeedback.Error()))
 	}
-	cmd = exec.Command("git", "stash", "pop")
-	_, _ = executeCommand(cmd, directory)
-	return func() tea.Msg {
-		return tui.ErrorMsg(fmt.Sprintf("Error copying grade file: %s", gradeFileName))
+	if errCopy := os.WriteFile(dstName, []byte(f.annotatedListing()), 0644); errCopy != nil {
+		return doneStep(result.failed(i18n.T("git.gradeFileCopy", gradeFileName)))
 	}
+	message, err := commitMessage(newCommitMessageData(directory, srcName, result, f))
+	if err != nil {
+		return doneStep(result.failed(err.Error()))
+	}
+	var tags []string
+	if viper.GetBool("gradetag") {
+		// The graded commit is tagged before the grading commit moves HEAD
+		tag := gradeTagName(parentDir)
+		if err = tagGradedCommit(directory, tag, f.gradedCommit, message); err != nil {
+			return doneStep(result.failed(i18n.T("git.tagFailed", tag, err)))
+		}
+		tags = append(tags, tag)
+	}
+	committed, err := gitBackend.Commit(directory, gradeFileName, message, configuredCommitOptions())
+	if err != nil {
+		return doneStep(result.failed(i18n.T("git.commitFailed", err)))
+	}
+	var str string
+	if !committed {
+		str = i18n.T("git.nothingToCommit")
+	}
+	return newStep(gitBackend.Push(directory, tags...), func(err error) Result {
+		if err != nil {
+			return result.failedWith(err)
+		}
+		return result.succeeded(str)
+	})
 }
 
-func checkIfDirectoryIsAGitRepo(directory os.DirEntry, sourceDirectory string) bool {
-	baseDir, _ := os.Getwd()
-	fullpath, _ := filepath.Abs(filepath.Join(sourceDirectory, directory.Name()))
-	if err := os.Chdir(fullpath); err != nil {
-		return false
+// configuredCommitOptions returns the author and the signature of the grading commits, as configured
+func configuredCommitOptions() commitOptions {
+	return commitOptions{
+		authorName:  viper.GetString("authorname"),
+		authorEmail: viper.GetString("authoremail"),
+		signing:     viper.GetString("signing"),
+		signingKey:  viper.GetString("signingkey"),
 	}
-	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
-	if out, err := cmd.Output(); err == nil {
-		_ = os.Chdir(baseDir)
-		if strings.Compare(string(out), fullpath+"\n") == 0 {
-			return true
+}
+
+// gitPrepareStarterRepository clones the starter code repository into the given path, or
+// updates it if it has already been cloned from the same URL
+func gitPrepareStarterRepository(url string, starterPath string) step {
+	result := Result{Repository: filepath.Base(starterPath), Action: actionDiff}
+	if _, err := os.Stat(starterPath); err == nil {
+		if origin, e := gitRunner.Output(starterPath, "remote", "get-url", "origin"); e != nil || strings.TrimSpace(string(origin)) != url {
+			_ = os.RemoveAll(starterPath)
 		}
 	}
-	_ = os.Chdir(baseDir)
-	return false
+	// The starter code is compared with git diff, so it is always fetched with git
+	var operation gitOperation
+	if _, err := os.Stat(starterPath); os.IsNotExist(err) {
+		operation = gitOperation{name: "clone", process: gitRunner.Command("", "clone", "-q", url, starterPath)}
+	} else {
+		operation = gitOperation{name: "pull", process: gitRunner.Command(starterPath, "pull", "-q", "--ff-only")}
+	}
+	return newStep(operation, func(err error) Result {
+		if err != nil {
+			return result.failed(i18n.T("git.starterError", err, url))
+		}
+		return result.succeeded("")
+	})
+}
+
+// gitDiffAgainstStarter writes a patch file with the diffstat and the full diff between the starter code
+// the student's repository started from and the repository, so only the changes made by the student are
+// shown
+func gitDiffAgainstStarter(starterPath string, directory string, patchFilename string) step {
+	result := repositoryResult(actionDiff, directory)
+	return newStep(gitOperation{}, func(error) Result {
+		if _, e := gitRunner.Output(directory, "fetch", "-q", "--no-tags", starterPath, "HEAD"); e != nil {
+			return result.failed(i18n.T("git.starterFetchFailed"))
+		}
+		// Comparing with the commit the student started from, so the changes made to the starter code
+		// afterward aren't shown as reverted by the student. Repositories created from a template share
+		// no history with it, and are compared with the starter code as it is now.
+		base := "FETCH_HEAD"
+		if out, e := gitRunner.Output(directory, "merge-base", "FETCH_HEAD", "HEAD"); e == nil {
+			base = strings.TrimSpace(string(out))
+		}
+		// The grading file is written by claro, not by the student
+		pathspec := []string{"--", ".", ":(exclude)" + viper.GetString("filename")}
+		stat, e := gitRunner.Output(directory, append([]string{"diff", "--stat", base, "HEAD"}, pathspec...)...)
+		if e != nil {
+			return result.failed(i18n.T("git.diffFailed"))
+		}
+		patch, e := gitRunner.Output(directory, append([]string{"diff", base, "HEAD"}, pathspec...)...)
+		if e != nil {
+			return result.failed(i18n.T("git.diffFailed"))
+		}
+		if e = os.WriteFile(patchFilename, append(append(stat, '\n'), patch...), 0644); e != nil {
+			return result.failed(i18n.T("git.patchWriteError", e))
+		}
+		shortStat, _ := gitRunner.Output(directory, append([]string{"diff", "--shortstat", base, "HEAD"}, pathspec...)...)
+		summary := strings.TrimSpace(string(shortStat))
+		if summary == "" {
+			summary = i18n.T("git.noChangesFromStarter")
+		}
+		return result.succeeded(summary)
+	})
 }
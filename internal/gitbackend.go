@@ -0,0 +1,285 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+)
+
+// Git backends
+const (
+	GitBackendExec   = "exec"
+	GitBackendNative = "native"
+)
+
+// GitBackend performs the git operations on the students' repositories
+type GitBackend interface {
+	// Clone clones the repository at url into path
+	Clone(url string, path string) gitOperation
+	// Pull integrates the remote commits of the current branch, keeping the local changes
+	Pull(directory string) gitOperation
+	// Push pushes the current branch to its remote, along with the tags, which replace the remote ones.
+	// With tags, nothing is pushed unless everything can be.
+	Push(directory string, tags ...string) gitOperation
+	// PushTags pushes only the tags, which replace the remote ones
+	PushTags(directory string, tags ...string) gitOperation
+	// Commit commits the file with the message, leaving the other local changes out of the commit.
+	// It reports false when the file has no changes to commit.
+	Commit(directory string, file string, message string, options commitOptions) (bool, error)
+	// Tag creates the annotated tag on the commit, replacing the existing one, signed as told by the options
+	Tag(directory string, name string, commit string, message string, options commitOptions) error
+	// Head returns the abbreviated hash and the date of the commit checked out
+	Head(directory string) (string, string, error)
+	// RemoteURL returns the URL of the origin remote
+	RemoteURL(directory string) (string, error)
+	// ResolveCommit returns the full hash of the commit named by the revision
+	ResolveCommit(directory string, revision string) (string, error)
+	// FileAt returns the content of the file in the commit
+	FileAt(directory string, commit string, path string) ([]byte, error)
+}
+
+// gitBackend performs the git operations, as selected with the --git-backend flag
+var gitBackend GitBackend = execGitBackend{}
+
+// SelectGitBackend selects the git backend given with the --git-backend flag
+func SelectGitBackend(name string) error {
+	switch name {
+	case GitBackendExec, "":
+		gitBackend = execGitBackend{}
+	case GitBackendNative:
+		gitBackend = nativeGitBackend{}
+	default:
+		return fmt.Errorf("invalid git backend '%s'. Valid backends are: %s, %s", name, GitBackendExec, GitBackendNative)
+	}
+	return nil
+}
+
+// UsesNativeGit reports whether the git operations are performed by the native backend, which doesn't
+// need git to be installed and authenticates with the GitHub Personal Access Token
+func UsesNativeGit() bool {
+	_, ok := gitBackend.(nativeGitBackend)
+	return ok
+}
+
+// gitOperation is a git operation that reaches a remote repository. The exec backend runs it as a git
+// process, attached to the terminal so git can prompt for credentials, and the native backend runs it
+// as a function. done, if set, is called when the operation is over, whether it failed or not.
+type gitOperation struct {
+	// name is the git command performed, such as "clone"
+	name    string
+	process *exec.Cmd
+	run     func() error
+	done    func()
+}
+
+// Commit signing formats
+const (
+	signingGPG = "gpg"
+	signingSSH = "ssh"
+)
+
+// commitOptions tell who authors the commits claro makes and how they are signed
+type commitOptions struct {
+	// authorName and authorEmail replace the identity of the user's git config, when set
+	authorName  string
+	authorEmail string
+	// signing is signingGPG, signingSSH or "" to sign as told by the user's git config
+	signing string
+	// signingKey is the GPG key ID or the SSH key file. When empty, the user's default key is used.
+	signingKey string
+}
+
+// Reasons a git operation failed
+const (
+	gitFailed = iota
+	gitAuthFailed
+	gitNonFastForward
+	gitNotFound
+	gitDiverged
+)
+
+// gitError is a failed git operation, with the reason it failed
+type gitError struct {
+	operation string
+	reason    int
+	err       error
+}
+
+func (e *gitError) Error() string {
+	switch e.reason {
+	case gitAuthFailed:
+		return i18n.T("git.errAuth", e.operation)
+	case gitNonFastForward:
+		return i18n.T("git.errNonFastForward", e.operation)
+	case gitNotFound:
+		return i18n.T("git.errNotFound", e.operation)
+	case gitDiverged:
+		return i18n.T("git.errDiverged", e.operation)
+	}
+	return i18n.T("git.errFailed", e.operation, e.err)
+}
+
+func (e *gitError) Unwrap() error {
+	return e.err
+}
+
+var (
+	// gitNonFastForwardPattern matches the messages git prints when the remote has commits the local
+	// repository doesn't
+	gitNonFastForwardPattern = regexp.MustCompile(`(?i)non-fast-forward|\[rejected\]|fetch first|not possible to fast-forward`)
+	// gitNotFoundPattern matches the messages git prints when the remote repository doesn't exist
+	gitNotFoundPattern = regexp.MustCompile(`(?i)repository '.*' (not found|does not exist)|does not appear to be a git repository|repository not found`)
+)
+
+// execGitError returns the gitError of a git process that failed, telling the reason from what it
+// wrote to stderr
+func execGitError(operation string, err error, stderr string) error {
+	if err == nil {
+		return nil
+	}
+	e := &gitError{operation: operation, err: err}
+	switch {
+	case gitAuthFailurePattern.MatchString(stderr):
+		e.reason = gitAuthFailed
+	case gitNonFastForwardPattern.MatchString(stderr):
+		e.reason = gitNonFastForward
+	case gitNotFoundPattern.MatchString(stderr):
+		e.reason = gitNotFound
+	default:
+		// The last line is usually the one telling what went wrong
+		lines := strings.Split(strings.TrimSpace(stderr), "\n")
+		if last := strings.TrimSpace(lines[len(lines)-1]); last != "" {
+			e.err = errors.New(strings.TrimPrefix(last, "fatal: "))
+		}
+	}
+	return e
+}
+
+// execGitBackend runs the git executable through gitRunner
+type execGitBackend struct{}
+
+func (execGitBackend) Clone(url string, path string) gitOperation {
+	return gitOperation{name: "clone", process: gitRunner.Command("", "clone", "-q", url, path)}
+}
+
+// Pull stashes the local changes, including the untracked files, while git rebases the local commits
+// onto the remote ones
+func (execGitBackend) Pull(directory string) gitOperation {
+	_, _ = gitRunner.Output(directory, "stash", "-a", "-q")
+	return gitOperation{
+		name:    "pull",
+		process: gitRunner.Command(directory, "pull", "-q", "--rebase"),
+		done: func() {
+			_, _ = gitRunner.Output(directory, "stash", "pop", "-q")
+		},
+	}
+}
+
+func (execGitBackend) Push(directory string, tags ...string) gitOperation {
+	args := []string{"push", "-q"}
+	if len(tags) > 0 {
+		args = append(args, "--atomic", "origin", "HEAD")
+		for _, tag := range tags {
+			args = append(args, "+refs/tags/"+tag+":refs/tags/"+tag)
+		}
+	}
+	return gitOperation{name: "push", process: gitRunner.Command(directory, args...)}
+}
+
+func (execGitBackend) PushTags(directory string, tags ...string) gitOperation {
+	args := []string{"push", "-q", "origin"}
+	for _, tag := range tags {
+		args = append(args, "+refs/tags/"+tag+":refs/tags/"+tag)
+	}
+	return gitOperation{name: "push", process: gitRunner.Command(directory, args...)}
+}
+
+func (execGitBackend) Commit(directory string, file string, message string, options commitOptions) (bool, error) {
+	if _, err := gitRunner.Output(directory, "add", file); err != nil {
+		return false, err
+	}
+	// Exits with 0 when the staged file is the same as in HEAD
+	if _, err := gitRunner.Output(directory, "diff", "--cached", "--quiet", "--", file); err == nil {
+		return false, nil
+	}
+	if _, err := gitRunner.Output(directory, options.commitArgs(message, file)...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (execGitBackend) Tag(directory string, name string, commit string, message string, options commitOptions) error {
+	args := options.identityArgs()
+	args = append(args, "tag", "-f", "-m", message)
+	switch {
+	case options.signing != "" && options.signingKey != "":
+		args = append(args, "-u", options.signingKey)
+	case options.signing != "":
+		args = append(args, "-s")
+	default:
+		args = append(args, "-a")
+	}
+	_, err := gitRunner.Output(directory, append(args, name, commit)...)
+	return err
+}
+
+// identityArgs returns the git options that override the author and the signing format of the user's
+// git config with the options
+func (o commitOptions) identityArgs() []string {
+	var args []string
+	if o.authorName != "" {
+		args = append(args, "-c", "user.name="+o.authorName)
+	}
+	if o.authorEmail != "" {
+		args = append(args, "-c", "user.email="+o.authorEmail)
+	}
+	switch o.signing {
+	case signingGPG:
+		args = append(args, "-c", "gpg.format=openpgp")
+	case signingSSH:
+		args = append(args, "-c", "gpg.format=ssh")
+	}
+	return args
+}
+
+// commitArgs returns the arguments of the git commit command, overriding the user's git config with
+// the options
+func (o commitOptions) commitArgs(message string, file string) []string {
+	args := append(o.identityArgs(), "commit", "-q")
+	if o.signing != "" {
+		args = append(args, "-S"+o.signingKey)
+	}
+	return append(args, "-m", message, "--", file)
+}
+
+func (execGitBackend) Head(directory string) (string, string, error) {
+	out, err := gitRunner.Output(directory, "show", "-s", "--format=%h%n%ci", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	hash, date, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return hash, date, nil
+}
+
+func (execGitBackend) RemoteURL(directory string) (string, error) {
+	out, err := gitRunner.Output(directory, "remote", "get-url", "origin")
+	return strings.TrimSpace(string(out)), err
+}
+
+func (execGitBackend) ResolveCommit(directory string, revision string) (string, error) {
+	out, err := gitRunner.Output(directory, "rev-parse", "--verify", "-q", revision+"^{commit}")
+	return strings.TrimSpace(string(out)), err
+}
+
+func (execGitBackend) FileAt(directory string, commit string, path string) ([]byte, error) {
+	return gitRunner.Output(directory, "show", commit+":"+path)
+}
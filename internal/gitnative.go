@@ -0,0 +1,412 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// nativeGitBackend performs the git operations with go-git, so claro works on machines without git
+// installed. It authenticates to GitHub over HTTPS with the user's GitHub Personal Access Token, and
+// pulls only when the local branch can be fast-forwarded.
+type nativeGitBackend struct{}
+
+// nativeAuth returns the credentials sent to the repository at url
+func nativeAuth(url string) transport.AuthMethod {
+	if tui.UserGitHubPAT == "" || !strings.HasPrefix(url, "https://") {
+		return nil
+	}
+	// GitHub accepts any username along with a Personal Access Token
+	return &githttp.BasicAuth{Username: "x-access-token", Password: tui.UserGitHubPAT}
+}
+
+// nativeOperation returns the operation that runs f on the repository in the directory, running it again
+// after a transient failure as told by the default retry policy. Each attempt is logged.
+func nativeOperation(name string, directory string, f func() error) gitOperation {
+	return gitOperation{name: name, run: func() error {
+		policy := defaultRetryPolicy
+		for attempt := 1; ; attempt++ {
+			started := time.Now()
+			err := f()
+			logNativeGit(name, directory, time.Since(started), err)
+			if errors.Is(err, git.NoErrAlreadyUpToDate) {
+				return nil
+			}
+			if err == nil || attempt >= policy.MaxAttempts || !nativeTransientFailure(err) {
+				return nativeGitError(name, err)
+			}
+			delay := policy.backoff(attempt)
+			retryNotice(i18n.T("retry.git", "git "+name, delay.Round(time.Second), attempt+1, policy.MaxAttempts))
+			time.Sleep(delay)
+		}
+	}}
+}
+
+// nativeTransientFailure reports whether go-git failed for a reason that may go away, such as a
+// dropped connection or an overloaded server
+func nativeTransientFailure(err error) bool {
+	var netError net.Error
+	var httpError *githttp.Err
+	switch {
+	case errors.As(err, &netError), errors.Is(err, io.ErrUnexpectedEOF):
+		return true
+	case errors.As(err, &httpError) && httpError.Response != nil:
+		code := httpError.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= http.StatusInternalServerError
+	}
+	return gitTransientFailurePattern.MatchString(err.Error())
+}
+
+// nativeGitError returns the gitError of a go-git operation that failed
+func nativeGitError(operation string, err error) error {
+	if err == nil {
+		return nil
+	}
+	e := &gitError{operation: operation, err: err}
+	switch {
+	case errors.Is(err, transport.ErrAuthenticationRequired), errors.Is(err, transport.ErrAuthorizationFailed):
+		e.reason = gitAuthFailed
+	case operation == "pull" && errors.Is(err, git.ErrNonFastForwardUpdate):
+		// go-git only fast-forwards, so it can't pull over a local commit, such as a grade commit whose
+		// push failed
+		e.reason = gitDiverged
+	case errors.Is(err, git.ErrNonFastForwardUpdate), errors.Is(err, git.ErrForceNeeded), strings.Contains(err.Error(), "non-fast-forward"):
+		e.reason = gitNonFastForward
+	case errors.Is(err, transport.ErrRepositoryNotFound), errors.Is(err, git.ErrRepositoryNotExists):
+		e.reason = gitNotFound
+	}
+	return e
+}
+
+// openBranch opens the repository in the directory and returns it with the URL of its origin remote and
+// the branch checked out
+func openBranch(directory string) (*git.Repository, string, plumbing.ReferenceName, error) {
+	r, err := git.PlainOpen(directory)
+	if err != nil {
+		return nil, "", "", err
+	}
+	url, err := nativeRemoteURL(r)
+	if err != nil {
+		return nil, "", "", err
+	}
+	head, err := r.Head()
+	if err != nil {
+		return nil, "", "", err
+	}
+	return r, url, head.Name(), nil
+}
+
+func nativeRemoteURL(r *git.Repository) (string, error) {
+	remote, err := r.Remote("origin")
+	if err != nil {
+		return "", err
+	}
+	if urls := remote.Config().URLs; len(urls) > 0 {
+		return urls[0], nil
+	}
+	return "", git.ErrRemoteNotFound
+}
+
+func (nativeGitBackend) Clone(url string, path string) gitOperation {
+	return nativeOperation("clone", path, func() error {
+		_, err := git.PlainClone(path, false, &git.CloneOptions{URL: url, Auth: nativeAuth(url)})
+		return err
+	})
+}
+
+func (nativeGitBackend) Pull(directory string) gitOperation {
+	return nativeOperation("pull", directory, func() error {
+		r, url, branch, err := openBranch(directory)
+		if err != nil {
+			return err
+		}
+		w, err := r.Worktree()
+		if err != nil {
+			return err
+		}
+		return w.Pull(&git.PullOptions{RemoteName: "origin", ReferenceName: branch, Auth: nativeAuth(url)})
+	})
+}
+
+func (nativeGitBackend) Push(directory string, tags ...string) gitOperation {
+	return nativeOperation("push", directory, func() error {
+		r, url, branch, err := openBranch(directory)
+		if err != nil {
+			return err
+		}
+		refSpecs := []gitconfig.RefSpec{gitconfig.RefSpec(branch.String() + ":" + branch.String())}
+		for _, tag := range tags {
+			ref := plumbing.NewTagReferenceName(tag).String()
+			refSpecs = append(refSpecs, gitconfig.RefSpec("+"+ref+":"+ref))
+		}
+		return r.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: refSpecs, Atomic: len(tags) > 0, Auth: nativeAuth(url)})
+	})
+}
+
+func (nativeGitBackend) PushTags(directory string, tags ...string) gitOperation {
+	return nativeOperation("push", directory, func() error {
+		r, url, _, err := openBranch(directory)
+		if err != nil {
+			return err
+		}
+		var refSpecs []gitconfig.RefSpec
+		for _, tag := range tags {
+			ref := plumbing.NewTagReferenceName(tag).String()
+			refSpecs = append(refSpecs, gitconfig.RefSpec("+"+ref+":"+ref))
+		}
+		return r.Push(&git.PushOptions{RemoteName: "origin", RefSpecs: refSpecs, Auth: nativeAuth(url)})
+	})
+}
+
+func (b nativeGitBackend) Commit(directory string, file string, message string, options commitOptions) (bool, error) {
+	started := time.Now()
+	committed, err := b.commit(directory, file, message, options)
+	logNativeGit("commit", directory, time.Since(started), err)
+	return committed, err
+}
+
+func (nativeGitBackend) commit(directory string, file string, message string, options commitOptions) (committed bool, err error) {
+	r, err := git.PlainOpen(directory)
+	if err != nil {
+		return false, err
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return false, err
+	}
+	head, err := r.Head()
+	if err != nil {
+		return false, err
+	}
+	// The commit is built from an index holding HEAD and the file only. The user's index is restored
+	// afterward, so the changes they staged are neither committed nor unstaged.
+	staged, err := r.Storer.Index()
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if e := restoreIndex(r, staged, file); err == nil {
+			err = e
+		}
+	}()
+	if err = w.Reset(&git.ResetOptions{Commit: head.Hash(), Mode: git.MixedReset}); err != nil {
+		return false, err
+	}
+	if _, err = w.Add(file); err != nil {
+		return false, err
+	}
+	status, err := w.Status()
+	if err != nil {
+		return false, err
+	}
+	if s, ok := status[file]; !ok || s.Staging == git.Unmodified {
+		return false, nil
+	}
+	commit := &git.CommitOptions{}
+	if options.authorName != "" || options.authorEmail != "" {
+		if commit.Author, err = nativeSignature(r, options); err != nil {
+			return false, err
+		}
+	}
+	if options.signing != "" {
+		commit.Signer = commandSigner{format: options.signing, key: options.signingKey}
+	}
+	if _, err = w.Commit(message, commit); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// restoreIndex replaces the index with the staged one, taking the file's entry from the current index
+func restoreIndex(r *git.Repository, staged *index.Index, file string) error {
+	current, err := r.Storer.Index()
+	if err != nil {
+		return err
+	}
+	if entry, err := current.Entry(file); err == nil {
+		if e, err := staged.Entry(file); err == nil {
+			*e = *entry
+		} else {
+			staged.Entries = append(staged.Entries, entry)
+		}
+	}
+	// The cached trees no longer match the entries
+	staged.Cache = nil
+	return r.Storer.SetIndex(staged)
+}
+
+// nativeSignature returns the identity of the commits and tags claro makes, taking from the user's git
+// config what is not set in the options
+func nativeSignature(r *git.Repository, options commitOptions) (*object.Signature, error) {
+	cfg, err := r.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return nil, err
+	}
+	s := &object.Signature{Name: cfg.User.Name, Email: cfg.User.Email, When: time.Now()}
+	if options.authorName != "" {
+		s.Name = options.authorName
+	}
+	if options.authorEmail != "" {
+		s.Email = options.authorEmail
+	}
+	return s, nil
+}
+
+// Tag builds the tag object itself, as go-git can only sign tags with a GPG key held in memory
+func (nativeGitBackend) Tag(directory string, name string, commit string, message string, options commitOptions) error {
+	started := time.Now()
+	err := nativeTag(directory, name, commit, message, options)
+	logNativeGit("tag", directory, time.Since(started), err)
+	return err
+}
+
+func nativeTag(directory string, name string, commit string, message string, options commitOptions) error {
+	r, err := git.PlainOpen(directory)
+	if err != nil {
+		return err
+	}
+	hash, err := r.ResolveRevision(plumbing.Revision(commit))
+	if err != nil {
+		return err
+	}
+	tagger, err := nativeSignature(r, options)
+	if err != nil {
+		return err
+	}
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *tagger,
+		Message:    strings.TrimRight(message, "\n") + "\n",
+		TargetType: plumbing.CommitObject,
+		Target:     *hash,
+	}
+	if options.signing != "" {
+		unsigned := &plumbing.MemoryObject{}
+		if err = tag.EncodeWithoutSignature(unsigned); err != nil {
+			return err
+		}
+		reader, err := unsigned.Reader()
+		if err != nil {
+			return err
+		}
+		signature, err := commandSigner{format: options.signing, key: options.signingKey}.Sign(reader)
+		if err != nil {
+			return err
+		}
+		tag.PGPSignature = string(signature)
+	}
+	encoded := r.Storer.NewEncodedObject()
+	if err = tag.Encode(encoded); err != nil {
+		return err
+	}
+	tagHash, err := r.Storer.SetEncodedObject(encoded)
+	if err != nil {
+		return err
+	}
+	return r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewTagReferenceName(name), tagHash))
+}
+
+// commandSigner signs the commits of the native backend as git does, running gpg or ssh-keygen
+type commandSigner struct {
+	format string
+	key    string
+}
+
+func (s commandSigner) Sign(message io.Reader) ([]byte, error) {
+	var cmd *exec.Cmd
+	if s.format == signingSSH {
+		if s.key == "" {
+			return nil, errors.New(i18n.T("git.sshSigningKey"))
+		}
+		// ssh-keygen signs what it reads from stdin and writes the signature to stdout
+		cmd = exec.Command("ssh-keygen", "-Y", "sign", "-n", "git", "-f", expandHomeDirectory(s.key))
+	} else {
+		args := []string{"--detach-sign", "--armor"}
+		if s.key != "" {
+			args = append(args, "--local-user", s.key)
+		}
+		cmd = exec.Command("gpg", args...)
+	}
+	var stderr bytes.Buffer
+	cmd.Stdin, cmd.Stderr = message, &stderr
+	signature, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", cmd.Args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return signature, nil
+}
+
+func (nativeGitBackend) Head(directory string) (string, string, error) {
+	r, err := git.PlainOpen(directory)
+	if err != nil {
+		return "", "", err
+	}
+	head, err := r.Head()
+	if err != nil {
+		return "", "", err
+	}
+	commit, err := r.CommitObject(head.Hash())
+	if err != nil {
+		return "", "", err
+	}
+	// The same format as git's %h and %ci
+	return head.Hash().String()[:7], commit.Committer.When.Format("2006-01-02 15:04:05 -0700"), nil
+}
+
+func (nativeGitBackend) RemoteURL(directory string) (string, error) {
+	r, err := git.PlainOpen(directory)
+	if err != nil {
+		return "", err
+	}
+	return nativeRemoteURL(r)
+}
+
+func (nativeGitBackend) ResolveCommit(directory string, revision string) (string, error) {
+	r, err := git.PlainOpen(directory)
+	if err != nil {
+		return "", err
+	}
+	hash, err := r.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return "", err
+	}
+	return hash.String(), nil
+}
+
+func (nativeGitBackend) FileAt(directory string, commit string, path string) ([]byte, error) {
+	r, err := git.PlainOpen(directory)
+	if err != nil {
+		return nil, err
+	}
+	c, err := r.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return nil, err
+	}
+	f, err := c.File(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := f.Contents()
+	return []byte(content), err
+}
@@ -0,0 +1,44 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"bytes"
+	"os/exec"
+	"time"
+)
+
+// GitRunner runs the git commands used by claro
+type GitRunner interface {
+	// Output runs git in the directory and returns what it writes to stdout
+	Output(directory string, args ...string) ([]byte, error)
+	// Command returns the git command to be run in the directory as the process of a step, attached
+	// to the terminal so git can prompt for credentials
+	Command(directory string, args ...string) *exec.Cmd
+}
+
+// gitRunner runs the git commands. Tests replace it with a fake.
+var gitRunner GitRunner = execGitRunner{}
+
+// execGitRunner runs the git executable found in the user's PATH
+type execGitRunner struct{}
+
+func (execGitRunner) Output(directory string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Stderr = &stderr
+	started := time.Now()
+	out, err := executeCommand(cmd, directory)
+	logGitCommand(directory, args, time.Since(started), err, stderr.String())
+	logGitOutput(args, out)
+	return out, err
+}
+
+func (execGitRunner) Command(directory string, args ...string) *exec.Cmd {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = directory
+	return cmd
+}
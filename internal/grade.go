@@ -0,0 +1,407 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+)
+
+const (
+	gradingProgressFilename = "grading.json"
+	previewMaxEntries       = 300
+	previewMaxDepth         = 3
+)
+
+// gradingProgress is the grading state saved between sessions of the grade command
+type gradingProgress struct {
+	Reviewed map[string]time.Time `json:"reviewed"`
+}
+
+func loadGradingProgress(submissionsDirectory string) gradingProgress {
+	p := gradingProgress{Reviewed: make(map[string]time.Time)}
+	if data, err := os.ReadFile(filepath.Join(stateDir(submissionsDirectory), gradingProgressFilename)); err == nil {
+		_ = json.Unmarshal(data, &p)
+	}
+	if p.Reviewed == nil {
+		p.Reviewed = make(map[string]time.Time)
+	}
+	return p
+}
+
+func saveGradingProgress(submissionsDirectory string, p gradingProgress) error {
+	if err := os.MkdirAll(stateDir(submissionsDirectory), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stateDir(submissionsDirectory), gradingProgressFilename), data, 0644)
+}
+
+// GradeModel represents the model for the grade command
+type GradeModel struct {
+	submissionsDirectory string
+	repos                repo
+	progress             gradingProgress
+	submissions          list.Model
+	preview              viewport.Model
+	score                textinput.Model
+	scoring              bool
+	ready                bool
+	quitting             bool
+	status               string
+	styles               tui.ClaroStyles
+	keyMap               *tui.GradingKeyMap
+	help                 help.Model
+	width                int
+	height               int
+}
+
+// NewGradeModel creates a new GradeModel
+func NewGradeModel(directory string) GradeModel {
+	directory, _ = filepath.Abs(expandHomeDirectory(directory))
+	ti := textinput.New()
+	ti.Prompt = i18n.T("grade.scorePrompt")
+	ti.CharLimit = 32
+	return GradeModel{
+		submissionsDirectory: directory,
+		progress:             loadGradingProgress(directory),
+		score:                ti,
+		styles:               tui.CreateDefaultStyles(),
+		keyMap:               tui.ClaroGradingKeyMap(),
+		help:                 help.New(),
+	}
+}
+
+func (m GradeModel) Init() tea.Cmd {
+	return getRepositoriesAndGradeFiles(m.submissionsDirectory)
+}
+
+func (m GradeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m = m.resize()
+		return m, nil
+	case tui.AssignmentDirError:
+		return m, tea.Sequence(tea.Printf("%s", tui.ErrorStyle.Render(string(msg)+"\n")), tea.Quit)
+	case repo:
+		m.repos = msg
+		if len(m.repos.repositories) == 0 {
+			return m, tea.Sequence(tea.Printf(tui.ErrorStyle.Render(i18n.T("dir.noGradeFiles", m.submissionsDirectory)+"\n")), tea.Quit)
+		}
+		sort.Slice(m.repos.repositories, func(i, j int) bool {
+			return m.repos.repositories[i].Name() < m.repos.repositories[j].Name()
+		})
+		items := make([]list.Item, 0, len(m.repos.repositories))
+		for _, r := range m.repos.repositories {
+			items = append(items, m.submissionItem(r.Name()))
+		}
+		l := list.New(items, tui.NewSubmissionDelegate(&m.styles), 0, 0)
+		l = tui.FormatList(l, i18n.T("grade.submissions"))
+		l.SetShowHelp(false)
+		l.KeyMap.NextPage = key.NewBinding(key.WithKeys("right", "l"))
+		l.KeyMap.PrevPage = key.NewBinding(key.WithKeys("left", "h"))
+		l.KeyMap.Quit.SetEnabled(false)
+		m.submissions = l
+		m.preview = viewport.New(0, 0)
+		m.ready = true
+		m = m.resize()
+		m = m.selectNextUngraded(-1)
+		return m, nil
+	case tui.EditorFinishedMsg:
+		if msg.Err != nil {
+			m.status = tui.ErrorStyle.UnsetMargins().Render(i18n.T("grade.editorError", msg.Err))
+		}
+		m = m.refreshSelected()
+		return m, nil
+	case tea.KeyMsg:
+		if !m.ready {
+			if msg.String() == "ctrl+c" {
+				return m, tea.Quit
+			}
+			return m, nil
+		}
+		if m.scoring {
+			return m.scoreUpdate(msg)
+		}
+		if m.submissions.FilterState() == list.Filtering {
+			break
+		}
+		switch {
+		case key.Matches(msg, m.keyMap.Quit):
+			m.quitting = true
+			return m, tea.Quit
+		case key.Matches(msg, m.keyMap.Edit):
+			return m, m.editSelected()
+		case key.Matches(msg, m.keyMap.NextUngraded):
+			m = m.selectNextUngraded(m.submissions.Index())
+			return m, nil
+		case key.Matches(msg, m.keyMap.Score):
+			if i, ok := m.submissions.SelectedItem().(tui.SubmissionItem); ok {
+				m.scoring = true
+				m.score.SetValue(i.Grade)
+				m.score.CursorEnd()
+				return m, m.score.Focus()
+			}
+			return m, nil
+		case key.Matches(msg, m.keyMap.Reviewed):
+			m = m.toggleReviewed()
+			return m, nil
+		case key.Matches(msg, m.keyMap.ScrollDown):
+			m.preview.HalfViewDown()
+			return m, nil
+		case key.Matches(msg, m.keyMap.ScrollUp):
+			m.preview.HalfViewUp()
+			return m, nil
+		}
+	}
+	if !m.ready {
+		return m, nil
+	}
+	previous := m.submissions.Index()
+	var cmd tea.Cmd
+	m.submissions, cmd = m.submissions.Update(msg)
+	if previous != m.submissions.Index() {
+		m = m.updatePreview()
+	}
+	return m, cmd
+}
+
+// scoreUpdate handles the keys while the grader is typing a score
+func (m GradeModel) scoreUpdate(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.scoring = false
+		m.score.Blur()
+		return m, nil
+	case "enter":
+		m.scoring = false
+		m.score.Blur()
+		if i, ok := m.submissions.SelectedItem().(tui.SubmissionItem); ok {
+			value := strings.TrimSpace(m.score.Value())
+			if err := writeGradeValue(m.gradeFilePath(i.Name), value); err != nil {
+				m.status = tui.ErrorStyle.UnsetMargins().Render(i18n.T("grade.writeError", err))
+			} else {
+				m.status = i18n.T("grade.scored", i.Name, value)
+			}
+		}
+		m = m.refreshSelected()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.score, cmd = m.score.Update(msg)
+	return m, cmd
+}
+
+func (m GradeModel) View() string {
+	if m.quitting || !m.ready {
+		return ""
+	}
+	graded, reviewed := m.counts()
+	header := m.styles.Title.Render(i18n.T("grade.header", graded, len(m.repos.repositories), reviewed, len(m.repos.repositories)))
+	left := tui.PaneStyle.Width(m.submissions.Width()).Render(m.submissions.View())
+	right := tui.PaneStyle.Render(m.preview.View())
+	footer := m.help.View(m.keyMap)
+	if m.scoring {
+		footer = m.score.View()
+	} else if m.status != "" {
+		footer = m.status + "\n" + footer
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, header, lipgloss.JoinHorizontal(lipgloss.Top, left, right), footer)
+}
+
+// SummaryLine returns the grading progress, to be shown once the program has quit
+func (m GradeModel) SummaryLine() string {
+	graded, reviewed := m.counts()
+	return i18n.T("grade.summary", graded, len(m.repos.repositories), reviewed)
+}
+
+// resize lays out the panes according to the terminal size
+func (m GradeModel) resize() GradeModel {
+	if !m.ready {
+		return m
+	}
+	// header, footer and the panes' borders
+	height := max(5, m.height-6)
+	leftWidth := max(30, m.width/3)
+	m.submissions.SetSize(leftWidth, height)
+	m.preview.Width = max(10, m.width-leftWidth-8)
+	m.preview.Height = height
+	m.help.Width = m.width
+	return m.updatePreview()
+}
+
+func (m GradeModel) gradeFilePath(repositoryName string) string {
+	return filepath.Join(m.submissionsDirectory, gradeFilename(repositoryName))
+}
+
+// submissionItem builds the list item of a repository from its grade file and the saved progress
+func (m GradeModel) submissionItem(repositoryName string) tui.SubmissionItem {
+	item := tui.SubmissionItem{Name: repositoryName, Status: tui.Ungraded}
+	item.Student = repositoryResult("", filepath.Join(m.submissionsDirectory, repositoryName)).Name
+	item.Grade, _ = readGradeValue(m.gradeFilePath(repositoryName))
+	if item.Grade != "" {
+		item.Status = tui.Scored
+	}
+	if _, ok := m.progress.Reviewed[repositoryName]; ok {
+		item.Status = tui.Reviewed
+	}
+	return item
+}
+
+func (m GradeModel) counts() (graded int, reviewed int) {
+	if !m.ready {
+		return 0, 0
+	}
+	for _, item := range m.submissions.Items() {
+		if i, ok := item.(tui.SubmissionItem); ok {
+			if i.Status != tui.Ungraded {
+				graded++
+			}
+			if i.Status == tui.Reviewed {
+				reviewed++
+			}
+		}
+	}
+	return graded, reviewed
+}
+
+// refreshSelected reloads the selected submission after its grade file or its review status has changed
+func (m GradeModel) refreshSelected() GradeModel {
+	if i, ok := m.submissions.SelectedItem().(tui.SubmissionItem); ok {
+		m.submissions.SetItem(m.submissions.Index(), m.submissionItem(i.Name))
+	}
+	return m.updatePreview()
+}
+
+func (m GradeModel) toggleReviewed() GradeModel {
+	i, ok := m.submissions.SelectedItem().(tui.SubmissionItem)
+	if !ok {
+		return m
+	}
+	if _, reviewed := m.progress.Reviewed[i.Name]; reviewed {
+		delete(m.progress.Reviewed, i.Name)
+	} else {
+		m.progress.Reviewed[i.Name] = time.Now()
+	}
+	if err := saveGradingProgress(m.submissionsDirectory, m.progress); err != nil {
+		m.status = tui.ErrorStyle.UnsetMargins().Render(i18n.T("grade.progressError", err))
+	}
+	return m.refreshSelected()
+}
+
+// selectNextUngraded selects the first ungraded submission after the given index, wrapping around the list
+func (m GradeModel) selectNextUngraded(from int) GradeModel {
+	items := m.submissions.Items()
+	for n := 1; n <= len(items); n++ {
+		index := (from + n + len(items)) % len(items)
+		if i, ok := items[index].(tui.SubmissionItem); ok && i.Status == tui.Ungraded {
+			m.submissions.Select(index)
+			m.status = ""
+			return m.updatePreview()
+		}
+	}
+	m.status = i18n.T("grade.allGraded")
+	return m.updatePreview()
+}
+
+// editSelected opens the grade file of the selected submission in the user's editor
+func (m GradeModel) editSelected() tea.Cmd {
+	i, ok := m.submissions.SelectedItem().(tui.SubmissionItem)
+	if !ok {
+		return nil
+	}
+	cmd := editorCommand(m.gradeFilePath(i.Name))
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return tui.EditorFinishedMsg{Err: err}
+	})
+}
+
+// updatePreview shows the selected repository's tree and README in the preview pane
+func (m GradeModel) updatePreview() GradeModel {
+	i, ok := m.submissions.SelectedItem().(tui.SubmissionItem)
+	if !ok {
+		m.preview.SetContent("")
+		return m
+	}
+	repositoryPath := filepath.Join(m.submissionsDirectory, i.Name)
+	var b strings.Builder
+	b.WriteString(m.styles.Title.Render(i.Name) + "\n\n")
+	b.WriteString(repositoryTree(repositoryPath))
+	if readme := findReadme(repositoryPath); readme != "" {
+		if content, err := os.ReadFile(readme); err == nil {
+			b.WriteString("\n" + m.styles.Title.Render(filepath.Base(readme)) + "\n\n")
+			b.WriteString(string(content))
+		}
+	}
+	m.preview.SetContent(lipgloss.NewStyle().Width(m.preview.Width).Render(b.String()))
+	m.preview.GotoTop()
+	return m
+}
+
+// repositoryTree renders the files of a repository as an indented tree, skipping the .git directory
+func repositoryTree(root string) string {
+	var b strings.Builder
+	entries := 0
+	_ = filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil || path == root {
+			return nil
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		rel, _ := filepath.Rel(root, path)
+		depth := strings.Count(rel, string(filepath.Separator))
+		if depth >= previewMaxDepth {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entries++; entries > previewMaxEntries {
+			b.WriteString("…\n")
+			return filepath.SkipAll
+		}
+		name := d.Name()
+		if d.IsDir() {
+			name += "/"
+		}
+		b.WriteString(strings.Repeat("  ", depth) + name + "\n")
+		return nil
+	})
+	return b.String()
+}
+
+// findReadme returns the path of the README file in the root of the repository, if any
+func findReadme(root string) string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasPrefix(strings.ToLower(entry.Name()), "readme") {
+			return filepath.Join(root, entry.Name())
+		}
+	}
+	return ""
+}
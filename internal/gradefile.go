@@ -0,0 +1,169 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+// gradeFilename returns the name of the grade file for a repository in the submissions directory
+func gradeFilename(repositoryName string) string {
+	return "grade-" + repositoryName + ".md"
+}
+
+// gradeLabels returns the grade strings the grade files in the submissions directory may be written with:
+// the current grade string, followed by the ones recorded in the manifest when the files were written
+func gradeLabels(submissionsDirectory string) []string {
+	var labels []string
+	if label := strings.TrimSpace(viper.GetString("grade")); label != "" {
+		labels = append(labels, label)
+	}
+	m, _ := loadManifest(submissionsDirectory)
+	for _, label := range m.Assignment.GradeLabels {
+		if !slices.Contains(labels, label) {
+			labels = append(labels, label)
+		}
+	}
+	return labels
+}
+
+// findGradeLine returns the index of the line containing the first of the grade strings found, and the
+// positions where the grade value starts and ends in that line
+func findGradeLine(lines []string, labels []string) (index int, start int, end int) {
+	for _, gradeString := range labels {
+		for i, line := range lines {
+			if pos := strings.Index(line, gradeString); pos >= 0 {
+				start = pos + len(gradeString)
+				// The grade string is usually written in bold, e.g. "- **Grade: 10**"
+				end = len(line)
+				if closing := strings.Index(line[start:], "**"); closing >= 0 {
+					end = start + closing
+				}
+				return i, start, end
+			}
+		}
+	}
+	return -1, 0, 0
+}
+
+// readGradeValue returns the grade written in the grade file, or an empty string if the file has not been graded yet
+func readGradeValue(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(string(content), "\n")
+	i, start, end := findGradeLine(lines, gradeLabels(filepath.Dir(path)))
+	if i < 0 {
+		return "", nil
+	}
+	return strings.TrimSpace(lines[i][start:end]), nil
+}
+
+// writeGradeValue writes the grade in the grade file, after the grade string. If the grade file
+// has no grade string, a grade line is appended to it.
+func writeGradeValue(path string, value string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	lines := strings.Split(string(content), "\n")
+	if i, start, end := findGradeLine(lines, gradeLabels(filepath.Dir(path))); i >= 0 {
+		lines[i] = strings.TrimRight(lines[i][:start], " ") + " " + value + lines[i][end:]
+	} else {
+		lines = append(lines, "- **"+strings.TrimSpace(viper.GetString("grade"))+" "+value+"**", "")
+		if err = recordGradeLabel(filepath.Dir(path)); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0644)
+}
+
+// memberPattern matches a team member's line of a group assignment's grade file, "- **@alice: +0.5**",
+// where the grader writes the member's adjustment to the team's grade
+var memberPattern = regexp.MustCompile(`^\s*[-*]\s+\*\*@([A-Za-z0-9][A-Za-z0-9-]*):\s*(.*?)\*\*\s*(.*?)\s*$`)
+
+// membersSection returns the section of a group assignment's grade file that holds the adjustments of
+// the team members to the team's grade
+func membersSection(logins []string) string {
+	section := "## " + i18n.T("defaults.members") + "\n\n"
+	for _, login := range logins {
+		section += "- **@" + login + ": ** \n"
+	}
+	return section + "\n"
+}
+
+// memberGrade is the grade of a member of a team: the team's grade with the member's adjustment
+type memberGrade struct {
+	Login      string
+	Adjustment string
+	Grade      string
+}
+
+// readMemberGrades returns the grades of the team members listed in a group assignment's grade file.
+// It returns nothing for individual assignments.
+func readMemberGrades(path string) ([]memberGrade, error) {
+	teamGrade, err := readGradeValue(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var grades []memberGrade
+	for _, line := range strings.Split(string(content), "\n") {
+		if match := memberPattern.FindStringSubmatch(line); match != nil {
+			adjustment := strings.TrimSpace(match[2])
+			if adjustment == "" {
+				// The adjustment may be written after the bold login, "- **@alice:** +0.5"
+				adjustment = match[3]
+			}
+			grades = append(grades, memberGrade{Login: match[1], Adjustment: adjustment, Grade: adjustGrade(teamGrade, adjustment)})
+		}
+	}
+	return grades, nil
+}
+
+// gradeNumberPattern matches the number a grade starts with, such as 8.5 in "8.5/10" or 8,5 in "8,5"
+var gradeNumberPattern = regexp.MustCompile(`^[+-]?\d+(?:[.,]\d+)?`)
+
+// adjustGrade applies a member's adjustment to the team's grade. An adjustment starting with + or - is
+// added to the team's grade, keeping what follows the number, so "8/10" adjusted by "+1" is "9/10".
+// Any other adjustment replaces the team's grade.
+func adjustGrade(teamGrade string, adjustment string) string {
+	switch {
+	case adjustment == "":
+		return teamGrade
+	case !strings.HasPrefix(adjustment, "+") && !strings.HasPrefix(adjustment, "-"):
+		return adjustment
+	}
+	base, ok := parseGradeNumber(teamGrade)
+	delta, ok2 := parseGradeNumber(adjustment)
+	if !ok || !ok2 {
+		return teamGrade
+	}
+	rest := strings.TrimPrefix(teamGrade, gradeNumberPattern.FindString(teamGrade))
+	return strconv.FormatFloat(base+delta, 'f', -1, 64) + rest
+}
+
+// parseGradeNumber returns the number a grade starts with
+func parseGradeNumber(grade string) (float64, bool) {
+	number := gradeNumberPattern.FindString(strings.TrimSpace(grade))
+	if number == "" {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(strings.Replace(number, ",", ".", 1), 64)
+	return value, err == nil
+}
@@ -0,0 +1,144 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestFindGradeLine(t *testing.T) {
+	tests := map[string]struct {
+		lines  []string
+		labels []string
+		index  int
+		value  string
+	}{
+		"bold":              {[]string{"# Feedback", "- **Grade: 8**"}, []string{"Grade:"}, 1, " 8"},
+		"not graded":        {[]string{"- **Grade: ** "}, []string{"Grade:"}, 0, " "},
+		"not bold":          {[]string{"Grade: 8/10"}, []string{"Grade:"}, 0, " 8/10"},
+		"text after":        {[]string{"- **Grade: 8** (late)"}, []string{"Grade:"}, 0, " 8"},
+		"first match":       {[]string{"- **Grade: 8**", "- **Grade: 9**"}, []string{"Grade:"}, 0, " 8"},
+		"no grade line":     {[]string{"# Feedback", "- ..."}, []string{"Grade:"}, -1, ""},
+		"no labels":         {[]string{"- **Grade: 8**"}, nil, -1, ""},
+		"previous label":    {[]string{"- **Grade: 8**"}, []string{"Nota:", "Grade:"}, 0, " 8"},
+		"current label":     {[]string{"- **Grade: 8**", "- **Nota: 9**"}, []string{"Nota:", "Grade:"}, 1, " 9"},
+		"label in the text": {[]string{"Final Grade: pending", "- **Grade: 8**"}, []string{"Grade:"}, 0, " pending"},
+	}
+	for name, tt := range tests {
+		index, start, end := findGradeLine(tt.lines, tt.labels)
+		if index != tt.index {
+			t.Errorf("%s: findGradeLine() index = %d, want %d", name, index, tt.index)
+			continue
+		}
+		if index >= 0 && tt.lines[index][start:end] != tt.value {
+			t.Errorf("%s: findGradeLine() value = %q, want %q", name, tt.lines[index][start:end], tt.value)
+		}
+	}
+}
+
+func TestReadWriteGradeValue(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("grade", "Grade: ")
+	tests := map[string]struct {
+		content string
+		value   string
+		want    string
+	}{
+		"not graded":    {"# Feedback\n\n- **Grade: ** \n", "8", "# Feedback\n\n- **Grade: 8** \n"},
+		"regraded":      {"# Feedback\n\n- **Grade: 8**\n", "9.5/10", "# Feedback\n\n- **Grade: 9.5/10**\n"},
+		"not bold":      {"Grade: 8\n", "9", "Grade: 9\n"},
+		"no grade line": {"# Feedback\n", "0", "# Feedback\n\n- **Grade: 0**\n"},
+	}
+	for name, tt := range tests {
+		path := filepath.Join(t.TempDir(), "grade-hw-alice.md")
+		writeFile(t, path, tt.content)
+		if err := writeGradeValue(path, tt.value); err != nil {
+			t.Fatal(err)
+		}
+		if content, _ := os.ReadFile(path); string(content) != tt.want {
+			t.Errorf("%s: grade file = %q, want %q", name, content, tt.want)
+		}
+		if grade, err := readGradeValue(path); err != nil || grade != tt.value {
+			t.Errorf("%s: readGradeValue() = %q, %v, want %q", name, grade, err, tt.value)
+		}
+	}
+
+	if _, err := readGradeValue(filepath.Join(t.TempDir(), "missing.md")); err == nil {
+		t.Error("readGradeValue of a missing file succeeded")
+	}
+}
+
+func TestMemberPattern(t *testing.T) {
+	tests := map[string][]string{
+		"- **@alice: +0.5**":     {"alice", "+0.5", ""},
+		"- **@alice: ** ":        {"alice", "", ""},
+		"* **@Bob-Dev: 7**":      {"Bob-Dev", "7", ""},
+		"- **@alice:** -1":       {"alice", "", "-1"},
+		"  - **@alice: -1** ok":  {"alice", "-1", "ok"},
+		"- **@-alice: +1**":      nil,
+		"- **alice: +1**":        nil,
+		"- **Grade: 8**":         nil,
+		"@alice: +1":             nil,
+		"- **@alice +1**":        nil,
+		"- **@alice_b: +1**":     nil,
+		"- **@alice: +1** extra": {"alice", "+1", "extra"},
+	}
+	for line, want := range tests {
+		var got []string
+		if match := memberPattern.FindStringSubmatch(line); match != nil {
+			got = match[1:]
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("memberPattern(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestAdjustGrade(t *testing.T) {
+	tests := []struct {
+		team, adjustment, want string
+	}{
+		{"8", "", "8"},
+		{"8", "+1", "9"},
+		{"8", "-0.5", "7.5"},
+		{"8/10", "+1", "9/10"},
+		{"8,5", "+0,5", "9"},
+		{"8.5 points", "-1", "7.5 points"},
+		{"8", "10", "10"},
+		{"8", "A", "A"},
+		{"B", "+1", "B"},
+		{"", "+1", ""},
+		{"8", "+x", "8"},
+		{"0", "-1", "-1"},
+	}
+	for _, tt := range tests {
+		if got := adjustGrade(tt.team, tt.adjustment); got != tt.want {
+			t.Errorf("adjustGrade(%q, %q) = %q, want %q", tt.team, tt.adjustment, got, tt.want)
+		}
+	}
+}
+
+func TestReadMemberGrades(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	viper.Set("grade", "Grade: ")
+	path := filepath.Join(t.TempDir(), "grade-hw-rockets.md")
+	members := strings.Replace(membersSection([]string{"alice", "bob", "carol"}), "- **@bob: ** ", "- **@bob: -2** ", 1)
+	writeFile(t, path, "# Feedback\n\n- **Grade: 8/10**\n\n"+members)
+	grades, err := readMemberGrades(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []memberGrade{{"alice", "", "8/10"}, {"bob", "-2", "6/10"}, {"carol", "", "8/10"}}
+	if !slices.Equal(grades, want) {
+		t.Errorf("readMemberGrades() = %+v, want %+v", grades, want)
+	}
+
+	writeFile(t, path, "# Feedback\n\n- **Grade: 8**\n")
+	if grades, err = readMemberGrades(path); err != nil || len(grades) != 0 {
+		t.Errorf("readMemberGrades() of an individual assignment = %+v, %v", grades, err)
+	}
+}
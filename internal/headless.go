@@ -0,0 +1,170 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/emersonmello/claro/internal/i18n"
+)
+
+// The functions in this file drive the same clone, pull, push and diff steps as the TUI models, but
+// sequentially and without a terminal. They are used by the text and json output modes.
+
+// RunClone clones the students' repositories of an assignment. If resume is set, the repositories
+// cloned by the interrupted run recorded in the journal are left out.
+func RunClone(assignmentId string, resume bool, r Reporter) error {
+	if assignmentId == "" {
+		return errors.New(i18n.T("clone.assignmentRequired"))
+	}
+	assignment, err := fetchAssignment(assignmentId)
+	if err != nil {
+		return err
+	}
+	accepted, err := fetchAcceptedAssignments(assignmentId, 0, 0)
+	if err != nil {
+		return err
+	}
+	if len(accepted) == 0 {
+		return errors.New(i18n.T("clone.noSubmissions"))
+	}
+	directory := submissionsDirectory(accepted[0].Assignment)
+	if err = LoadAssignmentConfig(directory); err != nil {
+		return err
+	}
+	if err = updateManifest(directory, assignment, accepted); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, i18n.T("clone.manifestError", err))
+	}
+	r.Progress(i18n.T("clone.found", len(accepted)))
+	j := openJournal(directory)
+	if err = j.begin(actionClone, resume); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, i18n.T("journal.saveError", err))
+	}
+	pending := pendingAssignments(j, accepted)
+	reportResume(r, len(accepted), len(pending))
+	var summary Summary
+	for _, a := range pending {
+		result := j.clone(a).run()
+		j.record(actionClone, result)
+		summary.add(reportResult(r, result))
+	}
+	j.finish(actionClone)
+	r.Done(i18n.T("clone.done", summary.count(StatusSucceeded)), summary)
+	return summary.ExitError(false)
+}
+
+// RunPull pulls the students' repositories in the submissions directory
+func RunPull(directory string, r Reporter) error {
+	s, err := scanSubmissions(directory)
+	if err != nil {
+		return err
+	}
+	reportSkipped(r, s.skipped)
+	repositories := s.repositories
+	if len(repositories) == 0 {
+		return errors.New(i18n.T("dir.noRepositories", directory))
+	}
+	r.Progress(i18n.T("pull.pulling", len(repositories)))
+	var summary Summary
+	for _, entry := range repositories {
+		fullpath, _ := filepath.Abs(filepath.Join(directory, entry.Name()))
+		summary.add(reportResult(r, gitPull(fullpath).run()))
+	}
+	r.Done(i18n.T("pull.done", summary.count(StatusSucceeded)), summary)
+	return summary.ExitError(false)
+}
+
+// RunPush delivers the grade files of the students' repositories in the submissions directory. If
+// resume is set, the repositories graded by the interrupted run recorded in the journal are left out.
+func RunPush(directory string, resume bool, r Reporter) error {
+	repos, err := repositoriesAndGradeFiles(directory)
+	if err != nil {
+		return err
+	}
+	reportSkipped(r, repos.skipped)
+	if len(repos.repoMap) == 0 {
+		return errors.New(i18n.T("dir.noGradeFiles", directory))
+	}
+	r.Progress(i18n.T("push.grading"))
+	j := openJournal(expandHomeDirectory(directory))
+	if err = j.begin(actionPush, resume); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, i18n.T("journal.saveError", err))
+	}
+	pending := pendingRepositories(j, actionPush, repos.repositories)
+	reportResume(r, len(repos.repositories), len(pending))
+	var summary Summary
+	for _, entry := range pending {
+		fullpath, _ := filepath.Abs(filepath.Join(directory, entry.Name()))
+		j.start(actionPush, entry.Name())
+		// The repository is pulled before the feedback is delivered
+		result := gitPull(fullpath).run()
+		if result.Status == StatusSucceeded {
+			result = gitDeliverFeedback(fullpath, repos.repoMap[entry.Name()]).run()
+		}
+		result.Action = actionPush
+		j.record(actionPush, result)
+		summary.add(reportResult(r, result))
+	}
+	j.finish(actionPush)
+	r.Done(i18n.T("push.done", summary.count(StatusSucceeded)), summary)
+	return summary.ExitError(false)
+}
+
+// RunDiff compares the students' repositories in the submissions directory with the starter code
+func RunDiff(directory string, starterRepository string, r Reporter) error {
+	directory, _ = filepath.Abs(expandHomeDirectory(directory))
+	url, err := starterRepositoryURL(directory, starterRepository)
+	if err != nil {
+		return err
+	}
+	r.Progress(i18n.T("diff.fetchingStarter", url))
+	starterPath := filepath.Join(stateDir(directory), "starter")
+	if result := gitPrepareStarterRepository(url, starterPath).run(); result.Status != StatusSucceeded {
+		return errors.New(result.Error)
+	}
+	s, err := scanSubmissions(directory)
+	if err != nil {
+		return err
+	}
+	reportSkipped(r, s.skipped)
+	repositories := s.repositories
+	if len(repositories) == 0 {
+		return errors.New(i18n.T("dir.noRepositories", directory))
+	}
+	r.Progress(i18n.T("diff.comparing", len(repositories)))
+	var summary Summary
+	for _, entry := range repositories {
+		fullpath := filepath.Join(directory, entry.Name())
+		patchFilename := filepath.Join(directory, "diff-"+entry.Name()+".patch")
+		summary.add(reportResult(r, gitDiffAgainstStarter(starterPath, fullpath, patchFilename).run()))
+	}
+	r.Done(i18n.T("diff.done", summary.count(StatusSucceeded)), summary)
+	return summary.ExitError(false)
+}
+
+// reportResume reports how many repositories were left out because the interrupted run already
+// processed them
+func reportResume(r Reporter, total int, pending int) {
+	if message := resumeMessage(total, pending); message != "" {
+		r.Progress(message)
+	}
+}
+
+// reportSkipped reports why directories of the submissions directory were ignored, when --verbose is set
+func reportSkipped(r Reporter, skipped []skippedDirectory) {
+	for _, message := range skippedMessages(skipped) {
+		r.Progress(message)
+	}
+}
+
+// reportResult reports the result and returns it
+func reportResult(r Reporter, result Result) Result {
+	r.Report(result)
+	return result
+}
@@ -0,0 +1,275 @@
+package i18n
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+var en = map[string]string{
+	// Grade file and commit defaults
+	"defaults.message": "This project has been graded. The file containing the grade is located in the root directory.",
+	"defaults.title":   "Feedback",
+	"defaults.grade":   "Grade: ",
+	"defaults.members": "Team members",
+	"defaults.student": "Student",
+
+	// Usage
+	"usage.error": "The '%s' command requires a directory containing student repositories and their corresponding grade files.",
+	"usage.structure": "\nThe directory should have been created using the 'clone' command and should include:" +
+		"\n- Subdirectories, each named after a student's repository (e.g., assignment-01-JohnDoeStudent)." +
+		"\n- Markdown files, each named with the pattern 'grade-<repository-name>.md' (e.g., grade-assignment-01-JohnDoeStudent.md).",
+	"usage.ensure": "\n\nEnsure that this structure is followed for the '%s' command to work correctly.\n",
+
+	// Keys
+	"key.back":         "to go back",
+	"key.confirm":      "confirm",
+	"key.edit":         "edit grade file",
+	"key.nextUngraded": "next ungraded",
+	"key.score":        "score",
+	"key.reviewed":     "mark reviewed",
+	"key.scrollDown":   "scroll preview",
+	"key.scrollUp":     "scroll back",
+	"key.quit":         "quit",
+
+	// Submissions directory
+	"dir.notExist":           "The assignment directory does not exist. Please run the clone command first.",
+	"dir.readError":          "Failed to read the directory: %v",
+	"dir.noRepositories":     "No repositories found in %s",
+	"dir.noGradeFiles":       "No repositories or grade files found in %s\nPlease see the help for more information",
+	"dir.skipped":            "%s ignored: %s",
+	"dir.stateDir":           "claro's state directory",
+	"dir.hidden":             "hidden directory",
+	"dir.notARepository":     "not a git repository",
+	"dir.bare":               "bare repository, without a working tree to pull into or to commit the grading file to",
+	"dir.brokenGitFile":      "its .git file doesn't point to a git directory",
+	"dir.brokenGitDirectory": "its .git directory is incomplete",
+	"dir.noGradeFile":        "no grade file %s",
+
+	// Clone
+	"clone.fetchingClassrooms":    "fetching your classrooms",
+	"clone.retrievingAssignments": "retrieving your assignments",
+	"clone.retrievingAccepted":    "Retrieving accepted assignments.",
+	"clone.done":                  "Cloned %d repositories",
+	"clone.selectClassroom":       "Select a classroom",
+	"clone.selectAssignment":      "Select an assignment",
+	"clone.noClassrooms":          "You don't have GitHub Classrooms, or you do not have permission to access them.",
+	"clone.noAssignments":         "No assignments were found for this classroom, or you do not have permission to access them.",
+	"clone.found":                 "Found %d repositories. Cloning...",
+	"clone.manifestError":         "Unable to save the assignment manifest: %s",
+	"clone.noSubmissions":         "No student submissions were found for this assignment, or you do not have permission to access them.",
+	"clone.assignmentRequired":    "The --assignment flag is required when the output is not 'tui'",
+
+	// Pull
+	"pull.pulling": "Pulling %d repositories",
+	"pull.done":    "Pulled %d repositories",
+
+	// Push
+	"push.grading": "Grading submissions",
+	"push.done":    "Graded %d submissions",
+
+	// Diff
+	"diff.fetchingStarter": "Fetching starter code repository %s",
+	"diff.comparing":       "Comparing %d repositories with the starter code",
+	"diff.done":            "Compared %d repositories with the starter code. Patch files are named diff-<repository-name>.patch",
+	"diff.noStarter":       "No starter code repository is recorded for this assignment. Please provide one with the '--starter' flag.",
+
+	// Contributions
+	"contributions.heading":    "Contributions",
+	"contributions.author":     "Author",
+	"contributions.commits":    "Commits",
+	"contributions.added":      "Lines added",
+	"contributions.removed":    "Lines removed",
+	"contributions.writing":    "Writing the contribution reports of %d repositories",
+	"contributions.authors":    "%d authors, %d commits",
+	"contributions.failed":     "Unable to read the commits: %s",
+	"contributions.writeError": "Unable to write the contribution report: %s",
+	"contributions.done":       "Wrote %d contribution reports",
+
+	// Grade
+	"grade.scorePrompt":   "Score: ",
+	"grade.submissions":   "Submissions",
+	"grade.editorError":   "Error running the editor: %s",
+	"grade.writeError":    "Unable to write the grade: %s",
+	"grade.scored":        "%s scored %s",
+	"grade.header":        "Graded %d/%d · reviewed %d/%d",
+	"grade.summary":       "Graded %d of %d submissions, %d reviewed",
+	"grade.tuiRequired":   "The grade command is interactive and requires the 'tui' output",
+	"grade.progressError": "Unable to save the grading progress: %s",
+	"grade.allGraded":     "All submissions have been graded",
+
+	// Summary
+	"summary.counts":    "%d succeeded, %d skipped, %d failed",
+	"summary.skipped":   "Skipped:",
+	"summary.failed":    "Failed:",
+	"summary.failures":  "%d of %d repositories failed",
+	"summary.tuiFailed": "Unable to run the interactive interface",
+
+	// Run journal
+	"journal.resuming":        "Resuming the interrupted run: %d repositories were already done",
+	"journal.nothingToResume": "Nothing to resume: all repositories were already done",
+	"journal.saveError":       "Unable to save the run journal: %s",
+
+	// Retries
+	"retry.rateLimit": "waiting for rate limit (%s)",
+	"retry.git":       "'%s' failed, retrying in %s (attempt %d of %d)",
+
+	// Regraded since
+	"regraded.untagged": "%d repositories have no %s tag",
+	"regraded.none":     "No student committed after the commit tagged %s",
+	"regraded.commits":  "%d commits after %s, the last on %s",
+
+	// Export
+	"export.written":         "Exported the grades of %d students to %s",
+	"export.unmapped":        "%d students lack the ID or email that identifies them in the mapping file and were left out: %s",
+	"export.unmappedListed":  "%d students are not in the mapping file: %s",
+	"export.ungraded":        "%d students have no grade in their grade file and were left out: %s",
+	"export.mappingTemplate": "Wrote %s listing the students: fill in their institutional ID, email and name, and export again",
+	"export.invalidFormat":   "invalid export format '%s'. Valid formats are: %s",
+
+	// Roster
+	"roster.empty":       "No students found in %s",
+	"roster.imported":    "Imported %d students to the course roster %s",
+	"roster.unlinked":    "%d students have no GitHub login in the roster yet",
+	"roster.none":        "No course roster found. Import one with: claro roster import <roster.csv>",
+	"roster.noDirectory": "No course directory to store the roster in. Give it after the roster file, or set the clone root directory: claro config set cloneroot <directory>",
+	"roster.allAccepted": "All the %d students in the roster accepted the assignment and pushed their work",
+	"roster.missing":     "%d of the %d students in the roster have no submission to grade",
+	"absent.noLogin":     "No GitHub account linked to the roster",
+	"absent.notAccepted": "Never accepted the assignment",
+	"absent.noCommits":   "No commits beyond the starter code",
+	"absent.zeroGrades":  "Gave a zero grade to %d students",
+
+	// Profiles
+	"profile.staleDefault": "The default profile '%s' no longer exists; using the main config file. Choose another with 'claro config profile use <name>'",
+	"profile.notFound":     "profile '%s' not found. Create it with 'claro config profile create %s'",
+	"profile.invalidName":  "invalid profile name '%s'. Use only letters, digits, '-' and '_'",
+	"profile.exists":       "profile '%s' already exists",
+	"profile.created":      "Profile '%s' created at %s",
+	"profile.createdHint":  "Use 'claro --profile %s config' to configure it, and 'claro --profile %s token add' to store its token",
+	"profile.defaultSet":   "Profile '%s' is now the default profile",
+	"profile.none":         "No profiles found. Create one with 'claro config profile create <name>'",
+	"profile.active":       "(active)",
+
+	// Config file and migration
+	"configFile.notFound":       "The config file %s does not exist",
+	"configFile.created":        "Created config file %s",
+	"configFile.readError":      "Unable to read the config file: %s",
+	"migrate.failed":            "Unable to migrate the config file %s: %s",
+	"migrate.migrated":          "The config file %s was migrated to version %d:",
+	"migrate.change":            "v%d → v%d: %s",
+	"migrate.added":             "added '%s' = '%v'",
+	"migrate.invalidVersion":    "invalid config version '%v'",
+	"migrate.newerVersion":      "the config file version %d was written by a newer claro (this one supports up to version %d)",
+	"migrate.unsupportedFormat": "unsupported config format '%s'. Supported formats are: %s",
+	"migrate.exists":            "%s already exists",
+	"migrate.converted":         "converted %s to %s",
+	"migrate.backup":            "previous config file saved as %s",
+	"migrate.upToDate":          "The config file %s is up to date (version %d)",
+
+	// Logs
+	"logs.none": "No log found in %s",
+
+	// Git
+	"git.mkdirError":           "Error creating directory: %s",
+	"git.cloneError":           "Error '%s' encountered while cloning: %s",
+	"git.rubricError":          "Unable to read rubric file: %s",
+	"git.gradeFileCreate":      "Unable to create grade file: %s",
+	"git.gradeFileWrite":       "Unable to write to markdown file: %s",
+	"git.alreadyExists":        "Repository already exists, skipping clone",
+	"git.newCommits":           "new commits",
+	"git.nothingToCommit":      "nothing to commit, working tree clean",
+	"git.gradeFileCopy":        "Error copying grade file: %s",
+	"git.starterError":         "Error '%s' encountered while fetching the starter code repository: %s",
+	"git.starterFetchFailed":   "Unable to fetch the starter code repository",
+	"git.diffFailed":           "Failed to execute 'git diff'",
+	"git.patchWriteError":      "Unable to write patch file: %s",
+	"git.noChangesFromStarter": "no changes from the starter code",
+	"git.repaired":             "partial clone repaired",
+	"git.zeroGradeReplaced":    "replaced the zero grade given before the assignment was accepted",
+	"git.repairError":          "Unable to remove the partial clone: %s",
+	"git.commitFailed":         "Unable to commit the grading file: %s",
+	"git.sshSigningKey":        "SSH commit signing needs the key file in the signingkey setting",
+	"git.messageTemplate":      "Invalid commit message template: %s",
+	"git.gradedCommitNotFound": "the graded commit %s was not found in the repository",
+	"git.tagFailed":            "Unable to tag the graded commit as %s: %s",
+	"git.errAuth":              "git %s: GitHub rejected the credentials",
+	"git.errNonFastForward":    "git %s rejected: the remote repository has commits the local one doesn't have (non-fast-forward)",
+	"git.errNotFound":          "git %s: repository not found",
+	"git.errDiverged":          "git %s: the local repository has commits the remote one doesn't have, such as a grade commit that wasn't pushed, and the native git backend can't rebase them. Run the command again with --git-backend=exec",
+	"git.errFailed":            "git %s failed: %s",
+	"git.required":             "the %s command needs 'git' installed and in the user PATH, even with the native git backend",
+
+	// Feedback delivery
+	"feedback.unknownDelivery":          "Unknown feedback delivery mode: %s",
+	"feedback.findPRFailed":             "Failed to find the Feedback pull request",
+	"feedback.noPullRequest":            "no Feedback pull request found",
+	"feedback.originUnreadable":         "unable to read the origin remote",
+	"feedback.notGitHub":                "the origin remote is not a GitHub repository: %s",
+	"feedback.postFailed":               "Failed to post the feedback on pull request #%d",
+	"feedback.reviewPosted":             "review posted on pull request #%d",
+	"feedback.reviewUpdated":            "review updated on pull request #%d",
+	"feedback.reviewManifest":           "Review delivered on pull request #%d, but unable to record it in the manifest: %s",
+	"feedback.commentPosted":            "comment posted on pull request #%d",
+	"feedback.commentUpdated":           "comment updated on pull request #%d",
+	"feedback.commentManifest":          "Comment delivered on pull request #%d, but unable to record it in the manifest: %s",
+	"feedback.issueFailed":              "Failed to deliver the feedback issue",
+	"feedback.issueCreated":             "issue #%d created",
+	"feedback.issueUpdated":             "issue #%d updated",
+	"feedback.issueManifest":            "Issue #%d delivered, but unable to record it in the manifest: %s",
+	"feedback.annotations":              "Annotations",
+	"feedback.annotationFileNotFound":   "@%s:%d: file not found in commit %.7s",
+	"feedback.annotationLineOutOfRange": "@%s:%d: line out of range, the file has %d lines",
+	"feedback.invalidAnnotations":       "invalid annotations in %s:",
+
+	// GitHub REST API
+	"rest.classroomsFailed":  "Failed to retrieve the classrooms list",
+	"rest.assignmentsFailed": "Failed to retrieve the assignments list",
+	"rest.assignmentFailed":  "Failed to retrieve the assignment",
+	"rest.acceptedFailed":    "Failed to retrieve the accepted assignments list",
+	"rest.clientError":       "An error occurred while retrieving the GitHub REST API client. %s",
+	"rest.badCredentials": "%s => HTTP %d: %s." +
+		"\nVisit https://docs.github.com/en/authentication/keeping-your-account-and-data-secure/managing-your-personal-access-tokens to generate a new PAT" +
+		"\nThen, execute '%s' to update your GitHub Personal Access Token.",
+
+	// Token
+	"token.storeError":      "Could not store token in operating system keyring:\n => %s",
+	"token.stored":          "Your github personal access token has been successfully set in the operating system keyring!",
+	"token.prompt":          "Provide your GitHub Personal Access Token (classic):",
+	"token.noTerminal":      "No terminal to prompt for the GitHub Personal Access Token: set it in the %s environment variable, or store it with 'claro token add'",
+	"token.missing":         "No GitHub Personal Access Token was given",
+	"token.confirmDelete":   "Are you sure you want to delete the GitHub Personal Access Token from the operating system keyring?",
+	"token.secretNotFound":  "Secret not found in OS Keychain.",
+	"token.deleteError":     "Error deleting token from OS Keychain: %s",
+	"token.deleted":         "Token deleted from OS Keychain",
+	"token.nothingToDelete": "No token found in OS Keychain. Nothing to delete.",
+	"token.confirmOverride": "A GitHub Personal Access Token for claro is already stored in the operating system keyring. Would you like to override it?",
+
+	// Config menu
+	"config.menu":              "Which option do you want to configure?",
+	"config.filename":          "Grade filename",
+	"config.message":           "Commit message",
+	"config.title":             "Grade file's title",
+	"config.grade":             "Grade file's grade string",
+	"config.delivery":          "Feedback delivery",
+	"config.label":             "Feedback issue's label",
+	"config.rubric":            "Grade file's rubric",
+	"config.host":              "GitHub API host",
+	"config.cloneRoot":         "Default clone directory",
+	"config.language":          "Language",
+	"config.quit":              "Quit",
+	"config.runError":          "There was an error running the program:",
+	"config.filenameHelp":      "The name of the file that will be created in the student repository containing the feedback.",
+	"config.messageHelp":       "Commit message for grading. It may use the fields {{.Student}}, {{.Name}}, {{.Repository}}, {{.Grade}}, {{.Assignment}}, {{.Commit}} and {{.Grader}}",
+	"config.titleHelp":         "The file's title representing the grade sheet",
+	"config.gradeHelp":         "The grade string inserted in the file representing the grade sheet.",
+	"config.deliveryHelp":      "How the feedback is delivered to the students",
+	"config.deliveryCommit":    "Commit the grade file to the student's repository",
+	"config.deliveryReview":    "Post the grade file as a review on the Feedback pull request",
+	"config.deliveryComment":   "Post the grade file as a comment on the Feedback pull request",
+	"config.deliveryIssue":     "Create or update an issue titled with the grade file's title",
+	"config.labelHelp":         "The label applied to the feedback issue when the feedback is delivered as an issue",
+	"config.rubricHelp":        "Path of a Markdown file whose content is inserted in each new grade file (leave it empty for none)",
+	"config.hostHelp":          "The GitHub host used to access the GitHub Classroom API (e.g., github.com)",
+	"config.cloneRootHelp":     "The directory where the assignments are cloned (leave it empty for the current directory)",
+	"config.languageHelp":      "The language of claro's messages",
+	"config.languageAutomatic": "Automatic (from the LANG environment variable)",
+}
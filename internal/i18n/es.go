@@ -0,0 +1,275 @@
+package i18n
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+var es = map[string]string{
+	// Grade file and commit defaults
+	"defaults.message": "Este proyecto ha sido calificado. El archivo con la calificación se encuentra en el directorio raíz.",
+	"defaults.title":   "Retroalimentación",
+	"defaults.grade":   "Calificación: ",
+	"defaults.members": "Miembros del equipo",
+	"defaults.student": "Estudiante",
+
+	// Usage
+	"usage.error": "El comando '%s' requiere un directorio con los repositorios de los estudiantes y sus archivos de calificación.",
+	"usage.structure": "\nEl directorio debe haber sido creado por el comando 'clone' y debe contener:" +
+		"\n- Subdirectorios, cada uno con el nombre del repositorio de un estudiante (ej.: assignment-01-JuanEstudiante)." +
+		"\n- Archivos Markdown, cada uno con el nombre según el patrón 'grade-<nombre-del-repositorio>.md' (ej.: grade-assignment-01-JuanEstudiante.md).",
+	"usage.ensure": "\n\nAsegúrese de seguir esta estructura para que el comando '%s' funcione correctamente.\n",
+
+	// Keys
+	"key.back":         "volver",
+	"key.confirm":      "confirmar",
+	"key.edit":         "editar archivo de calificación",
+	"key.nextUngraded": "siguiente sin calificar",
+	"key.score":        "calificar",
+	"key.reviewed":     "marcar como revisada",
+	"key.scrollDown":   "desplazar vista previa",
+	"key.scrollUp":     "retroceder vista previa",
+	"key.quit":         "salir",
+
+	// Submissions directory
+	"dir.notExist":           "El directorio de la tarea no existe. Ejecute primero el comando clone.",
+	"dir.readError":          "Error al leer el directorio: %v",
+	"dir.noRepositories":     "No se encontraron repositorios en %s",
+	"dir.noGradeFiles":       "No se encontraron repositorios ni archivos de calificación en %s\nConsulte la ayuda para más información",
+	"dir.skipped":            "%s ignorado: %s",
+	"dir.stateDir":           "directorio de estado de claro",
+	"dir.hidden":             "directorio oculto",
+	"dir.notARepository":     "no es un repositorio git",
+	"dir.bare":               "repositorio bare, sin directorio de trabajo para recibir el pull o el commit del archivo de calificación",
+	"dir.brokenGitFile":      "su archivo .git no apunta a un directorio git",
+	"dir.brokenGitDirectory": "su directorio .git está incompleto",
+	"dir.noGradeFile":        "sin el archivo de calificación %s",
+
+	// Clone
+	"clone.fetchingClassrooms":    "obteniendo sus aulas",
+	"clone.retrievingAssignments": "obteniendo sus tareas",
+	"clone.retrievingAccepted":    "Obteniendo las tareas aceptadas.",
+	"clone.done":                  "%d repositorios clonados",
+	"clone.selectClassroom":       "Seleccione un aula",
+	"clone.selectAssignment":      "Seleccione una tarea",
+	"clone.noClassrooms":          "No tiene aulas de GitHub Classroom, o no tiene permiso para acceder a ellas.",
+	"clone.noAssignments":         "No se encontraron tareas para esta aula, o no tiene permiso para acceder a ellas.",
+	"clone.found":                 "Se encontraron %d repositorios. Clonando...",
+	"clone.manifestError":         "No se pudo guardar el manifiesto de la tarea: %s",
+	"clone.noSubmissions":         "No se encontraron entregas para esta tarea, o no tiene permiso para acceder a ellas.",
+	"clone.assignmentRequired":    "La opción --assignment es obligatoria cuando la salida no es 'tui'",
+
+	// Pull
+	"pull.pulling": "Actualizando %d repositorios",
+	"pull.done":    "%d repositorios actualizados",
+
+	// Push
+	"push.grading": "Enviando las calificaciones",
+	"push.done":    "%d entregas calificadas",
+
+	// Diff
+	"diff.fetchingStarter": "Obteniendo el repositorio de código inicial %s",
+	"diff.comparing":       "Comparando %d repositorios con el código inicial",
+	"diff.done":            "Se compararon %d repositorios con el código inicial. Los archivos de parche se llaman diff-<nombre-del-repositorio>.patch",
+	"diff.noStarter":       "No hay un repositorio de código inicial registrado para esta tarea. Indique uno con la opción '--starter'.",
+
+	// Contributions
+	"contributions.heading":    "Contribuciones",
+	"contributions.author":     "Autor",
+	"contributions.commits":    "Commits",
+	"contributions.added":      "Líneas añadidas",
+	"contributions.removed":    "Líneas eliminadas",
+	"contributions.writing":    "Generando los informes de contribución de %d repositorios",
+	"contributions.authors":    "%d autores, %d commits",
+	"contributions.failed":     "No se pudieron leer los commits: %s",
+	"contributions.writeError": "No se pudo escribir el informe de contribución: %s",
+	"contributions.done":       "Se generaron %d informes de contribución",
+
+	// Grade
+	"grade.scorePrompt":   "Calificación: ",
+	"grade.submissions":   "Entregas",
+	"grade.editorError":   "Error al ejecutar el editor: %s",
+	"grade.writeError":    "No se pudo escribir la calificación: %s",
+	"grade.scored":        "%s calificado con %s",
+	"grade.header":        "Calificadas %d/%d · revisadas %d/%d",
+	"grade.summary":       "%d de %d entregas calificadas, %d revisadas",
+	"grade.tuiRequired":   "El comando grade es interactivo y requiere la salida 'tui'",
+	"grade.progressError": "No se pudo guardar el progreso de la calificación: %s",
+	"grade.allGraded":     "Todas las entregas han sido calificadas",
+
+	// Summary
+	"summary.counts":    "%d con éxito, %d omitidos, %d con error",
+	"summary.skipped":   "Omitidos:",
+	"summary.failed":    "Con error:",
+	"summary.failures":  "%d de %d repositorios fallaron",
+	"summary.tuiFailed": "No se pudo ejecutar la interfaz interactiva",
+
+	// Run journal
+	"journal.resuming":        "Reanudando la ejecución interrumpida: %d repositorios ya estaban completados",
+	"journal.nothingToResume": "Nada que reanudar: todos los repositorios ya estaban completados",
+	"journal.saveError":       "No se pudo guardar el diario de ejecución: %s",
+
+	// Retries
+	"retry.rateLimit": "esperando el límite de solicitudes (%s)",
+	"retry.git":       "'%s' falló, reintentando en %s (intento %d de %d)",
+
+	// Regraded since
+	"regraded.untagged": "%d repositorios no tienen la etiqueta %s",
+	"regraded.none":     "Ningún estudiante hizo commits después del commit etiquetado %s",
+	"regraded.commits":  "%d commits después de %s, el último el %s",
+
+	// Export
+	"export.written":         "Calificaciones de %d estudiantes exportadas a %s",
+	"export.unmapped":        "%d estudiantes no tienen en el archivo de mapeo el ID o el correo que los identifica y fueron excluidos: %s",
+	"export.unmappedListed":  "%d estudiantes no están en el archivo de mapeo: %s",
+	"export.ungraded":        "%d estudiantes no tienen nota en su archivo de calificación y fueron excluidos: %s",
+	"export.mappingTemplate": "Se creó %s con la lista de estudiantes: complete la matrícula, el email y el nombre de cada uno y exporte de nuevo",
+	"export.invalidFormat":   "formato de exportación '%s' no válido. Los formatos válidos son: %s",
+
+	// Roster
+	"roster.empty":       "No se encontraron estudiantes en %s",
+	"roster.imported":    "%d estudiantes importados a la lista del curso %s",
+	"roster.unlinked":    "%d estudiantes aún no tienen login de GitHub en la lista",
+	"roster.none":        "No se encontró la lista del curso. Importe una con: claro roster import <roster.csv>",
+	"roster.noDirectory": "Ningún directorio del curso para guardar la lista del curso. Indíquelo después del archivo de la lista o defina el directorio raíz de los clones: claro config set cloneroot <directorio>",
+	"roster.allAccepted": "Los %d estudiantes de la lista aceptaron la tarea y enviaron su trabajo",
+	"roster.missing":     "%d de los %d estudiantes de la lista no tienen entrega para calificar",
+	"absent.noLogin":     "Ninguna cuenta de GitHub vinculada a la lista",
+	"absent.notAccepted": "Nunca aceptó la tarea",
+	"absent.noCommits":   "Ningún commit además del código inicial",
+	"absent.zeroGrades":  "Calificación cero asignada a %d estudiantes",
+
+	// Profiles
+	"profile.staleDefault": "El perfil predeterminado '%s' ya no existe; se usa el archivo de configuración principal. Elija otro con 'claro config profile use <nombre>'",
+	"profile.notFound":     "perfil '%s' no encontrado. Créelo con 'claro config profile create %s'",
+	"profile.invalidName":  "nombre de perfil no válido '%s'. Use solo letras, dígitos, '-' y '_'",
+	"profile.exists":       "el perfil '%s' ya existe",
+	"profile.created":      "Perfil '%s' creado en %s",
+	"profile.createdHint":  "Use 'claro --profile %s config' para configurarlo y 'claro --profile %s token add' para guardar su token",
+	"profile.defaultSet":   "El perfil '%s' es ahora el perfil predeterminado",
+	"profile.none":         "No se encontraron perfiles. Cree uno con 'claro config profile create <nombre>'",
+	"profile.active":       "(activo)",
+
+	// Config file and migration
+	"configFile.notFound":       "El archivo de configuración %s no existe",
+	"configFile.created":        "Archivo de configuración %s creado",
+	"configFile.readError":      "No se pudo leer el archivo de configuración: %s",
+	"migrate.failed":            "No se pudo migrar el archivo de configuración %s: %s",
+	"migrate.migrated":          "El archivo de configuración %s se migró a la versión %d:",
+	"migrate.change":            "v%d → v%d: %s",
+	"migrate.added":             "clave '%s' añadida con '%v'",
+	"migrate.invalidVersion":    "versión de configuración no válida '%v'",
+	"migrate.newerVersion":      "la versión %d del archivo de configuración fue escrita por un claro más nuevo (este admite hasta la versión %d)",
+	"migrate.unsupportedFormat": "formato de configuración no admitido '%s'. Los formatos admitidos son: %s",
+	"migrate.exists":            "%s ya existe",
+	"migrate.converted":         "%s convertido a %s",
+	"migrate.backup":            "archivo de configuración anterior guardado como %s",
+	"migrate.upToDate":          "El archivo de configuración %s está actualizado (versión %d)",
+
+	// Logs
+	"logs.none": "No se encontró ningún log en %s",
+
+	// Git
+	"git.mkdirError":           "Error al crear el directorio: %s",
+	"git.cloneError":           "Error '%s' al clonar: %s",
+	"git.rubricError":          "No se pudo leer el archivo de rúbrica: %s",
+	"git.gradeFileCreate":      "No se pudo crear el archivo de calificación: %s",
+	"git.gradeFileWrite":       "No se pudo escribir en el archivo markdown: %s",
+	"git.alreadyExists":        "El repositorio ya existe, se omite la clonación",
+	"git.newCommits":           "nuevos commits",
+	"git.nothingToCommit":      "nada para confirmar, el árbol de trabajo está limpio",
+	"git.gradeFileCopy":        "Error al copiar el archivo de calificación: %s",
+	"git.starterError":         "Error '%s' al obtener el repositorio de código inicial: %s",
+	"git.starterFetchFailed":   "No se pudo obtener el repositorio de código inicial",
+	"git.diffFailed":           "Error al ejecutar 'git diff'",
+	"git.patchWriteError":      "No se pudo escribir el archivo de parche: %s",
+	"git.noChangesFromStarter": "sin cambios respecto al código inicial",
+	"git.repaired":             "clon parcial reparado",
+	"git.zeroGradeReplaced":    "reemplazada la calificación cero asignada antes de aceptar la tarea",
+	"git.repairError":          "No se pudo eliminar el clon parcial: %s",
+	"git.commitFailed":         "No se pudo hacer el commit del archivo de calificación: %s",
+	"git.sshSigningKey":        "La firma de commits con SSH necesita el archivo de la clave en la configuración signingkey",
+	"git.messageTemplate":      "Plantilla de mensaje de commit no válida: %s",
+	"git.gradedCommitNotFound": "el commit calificado %s no se encontró en el repositorio",
+	"git.tagFailed":            "No se pudo etiquetar el commit calificado como %s: %s",
+	"git.errAuth":              "git %s: GitHub rechazó las credenciales",
+	"git.errNonFastForward":    "git %s rechazado: el repositorio remoto tiene commits que el local no tiene (non-fast-forward)",
+	"git.errNotFound":          "git %s: repositorio no encontrado",
+	"git.errDiverged":          "git %s: el repositorio local tiene commits que el remoto no tiene, como un commit de calificación que no se envió, y el backend git nativo no puede hacer rebase de ellos. Ejecute el comando de nuevo con --git-backend=exec",
+	"git.errFailed":            "git %s falló: %s",
+	"git.required":             "el comando %s necesita 'git' instalado y en el PATH del usuario, incluso con el backend git nativo",
+
+	// Feedback delivery
+	"feedback.unknownDelivery":          "Modo de entrega de la retroalimentación desconocido: %s",
+	"feedback.findPRFailed":             "No se encontró el pull request Feedback",
+	"feedback.noPullRequest":            "no se encontró ningún pull request Feedback",
+	"feedback.originUnreadable":         "no se pudo leer el remoto origin",
+	"feedback.notGitHub":                "el remoto origin no es un repositorio de GitHub: %s",
+	"feedback.postFailed":               "Error al publicar la retroalimentación en el pull request #%d",
+	"feedback.reviewPosted":             "revisión publicada en el pull request #%d",
+	"feedback.reviewUpdated":            "revisión actualizada en el pull request #%d",
+	"feedback.reviewManifest":           "Revisión entregada en el pull request #%d, pero no se pudo registrar en el manifiesto: %s",
+	"feedback.commentPosted":            "comentario publicado en el pull request #%d",
+	"feedback.commentUpdated":           "comentario actualizado en el pull request #%d",
+	"feedback.commentManifest":          "Comentario entregado en el pull request #%d, pero no se pudo registrar en el manifiesto: %s",
+	"feedback.issueFailed":              "Error al entregar la issue de retroalimentación",
+	"feedback.issueCreated":             "issue #%d creada",
+	"feedback.issueUpdated":             "issue #%d actualizada",
+	"feedback.issueManifest":            "Issue #%d entregada, pero no se pudo registrar en el manifiesto: %s",
+	"feedback.annotations":              "Anotaciones",
+	"feedback.annotationFileNotFound":   "@%s:%d: archivo no encontrado en el commit %.7s",
+	"feedback.annotationLineOutOfRange": "@%s:%d: línea fuera de rango, el archivo tiene %d líneas",
+	"feedback.invalidAnnotations":       "anotaciones no válidas en %s:",
+
+	// GitHub REST API
+	"rest.classroomsFailed":  "Error al obtener la lista de aulas",
+	"rest.assignmentsFailed": "Error al obtener la lista de tareas",
+	"rest.assignmentFailed":  "Error al obtener la tarea",
+	"rest.acceptedFailed":    "Error al obtener la lista de tareas aceptadas",
+	"rest.clientError":       "Ocurrió un error al obtener el cliente de la API REST de GitHub. %s",
+	"rest.badCredentials": "%s => HTTP %d: %s." +
+		"\nVisite https://docs.github.com/es/authentication/keeping-your-account-and-data-secure/managing-your-personal-access-tokens para generar un nuevo PAT" +
+		"\nLuego, ejecute '%s' para actualizar su GitHub Personal Access Token.",
+
+	// Token
+	"token.storeError":      "No se pudo guardar el token en el llavero del sistema operativo:\n => %s",
+	"token.stored":          "¡Su GitHub Personal Access Token se guardó correctamente en el llavero del sistema operativo!",
+	"token.prompt":          "Ingrese su GitHub Personal Access Token (classic):",
+	"token.noTerminal":      "Sin terminal para solicitar el GitHub Personal Access Token: defínalo en la variable de entorno %s, o guárdelo con 'claro token add'",
+	"token.missing":         "No se proporcionó ningún GitHub Personal Access Token",
+	"token.confirmDelete":   "¿Está seguro de que desea eliminar el GitHub Personal Access Token del llavero del sistema operativo?",
+	"token.secretNotFound":  "Secreto no encontrado en el llavero del sistema operativo.",
+	"token.deleteError":     "Error al eliminar el token del llavero del sistema operativo: %s",
+	"token.deleted":         "Token eliminado del llavero del sistema operativo",
+	"token.nothingToDelete": "No se encontró ningún token en el llavero del sistema operativo. Nada que eliminar.",
+	"token.confirmOverride": "Ya hay un GitHub Personal Access Token de claro en el llavero del sistema operativo. ¿Desea reemplazarlo?",
+
+	// Config menu
+	"config.menu":              "¿Qué opción desea configurar?",
+	"config.filename":          "Nombre del archivo de calificación",
+	"config.message":           "Mensaje de commit",
+	"config.title":             "Título del archivo de calificación",
+	"config.grade":             "Texto de la calificación en el archivo",
+	"config.delivery":          "Entrega de la retroalimentación",
+	"config.label":             "Etiqueta de la issue de retroalimentación",
+	"config.rubric":            "Rúbrica del archivo de calificación",
+	"config.host":              "Host de la API de GitHub",
+	"config.cloneRoot":         "Directorio de clonación predeterminado",
+	"config.language":          "Idioma",
+	"config.quit":              "Salir",
+	"config.runError":          "Ocurrió un error al ejecutar el programa:",
+	"config.filenameHelp":      "El nombre del archivo con la retroalimentación que se creará en el repositorio del estudiante.",
+	"config.messageHelp":       "Mensaje de commit de la calificación. Puede usar los campos {{.Student}}, {{.Name}}, {{.Repository}}, {{.Grade}}, {{.Assignment}}, {{.Commit}} y {{.Grader}}",
+	"config.titleHelp":         "El título del archivo que representa la hoja de calificación",
+	"config.gradeHelp":         "El texto de la calificación insertado en el archivo que representa la hoja de calificación.",
+	"config.deliveryHelp":      "Cómo se entrega la retroalimentación a los estudiantes",
+	"config.deliveryCommit":    "Hacer commit del archivo de calificación en el repositorio del estudiante",
+	"config.deliveryReview":    "Publicar el archivo de calificación como revisión en el pull request Feedback",
+	"config.deliveryComment":   "Publicar el archivo de calificación como comentario en el pull request Feedback",
+	"config.deliveryIssue":     "Crear o actualizar una issue con el título del archivo de calificación",
+	"config.labelHelp":         "La etiqueta aplicada a la issue cuando la retroalimentación se entrega como issue",
+	"config.rubricHelp":        "Ruta de un archivo Markdown cuyo contenido se inserta en cada nuevo archivo de calificación (déjelo vacío para ninguno)",
+	"config.hostHelp":          "El host de GitHub usado para acceder a la API de GitHub Classroom (ej.: github.com)",
+	"config.cloneRootHelp":     "El directorio donde se clonan las tareas (déjelo vacío para el directorio actual)",
+	"config.languageHelp":      "El idioma de los mensajes de claro",
+	"config.languageAutomatic": "Automático (a partir de la variable de entorno LANG)",
+}
@@ -0,0 +1,89 @@
+// Package i18n provides the message catalogs used by claro's user interface and grade files.
+package i18n
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	English    = "en"
+	Portuguese = "pt-BR"
+	Spanish    = "es"
+)
+
+// Languages are the languages with a message catalog
+var Languages = []string{English, Portuguese, Spanish}
+
+var catalogs = map[string]map[string]string{
+	English:    en,
+	Portuguese: ptBR,
+	Spanish:    es,
+}
+
+var current = English
+
+func init() {
+	SetLanguage(Detect(""))
+}
+
+// Normalize maps a locale name, such as "pt_BR.UTF-8" or "es_AR", to the closest language with a message catalog
+func Normalize(locale string) string {
+	locale = strings.Split(locale, ":")[0]
+	locale = strings.Split(locale, ".")[0]
+	locale = strings.Split(locale, "@")[0]
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	switch {
+	case strings.HasPrefix(locale, "pt"):
+		return Portuguese
+	case strings.HasPrefix(locale, "es"):
+		return Spanish
+	default:
+		return English
+	}
+}
+
+// Detect returns the configured language or, if there is none, the language of the user's locale
+func Detect(configured string) string {
+	if configured != "" {
+		return Normalize(configured)
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" && v != "C" && v != "POSIX" {
+			return Normalize(v)
+		}
+	}
+	return English
+}
+
+// SetLanguage selects the message catalog used by T
+func SetLanguage(lang string) {
+	if _, ok := catalogs[lang]; ok {
+		current = lang
+	}
+}
+
+// Language returns the language in use
+func Language() string {
+	return current
+}
+
+// T returns the message in the language in use, formatted with the given arguments. Messages missing
+// from the catalog fall back to English.
+func T(key string, args ...any) string {
+	msg, ok := catalogs[current][key]
+	if !ok {
+		if msg, ok = en[key]; !ok {
+			msg = key
+		}
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
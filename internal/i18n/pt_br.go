@@ -0,0 +1,275 @@
+package i18n
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+var ptBR = map[string]string{
+	// Grade file and commit defaults
+	"defaults.message": "Este projeto foi avaliado. O arquivo com a nota está no diretório raiz.",
+	"defaults.title":   "Avaliação",
+	"defaults.grade":   "Nota: ",
+	"defaults.members": "Membros da equipe",
+	"defaults.student": "Estudante",
+
+	// Usage
+	"usage.error": "O comando '%s' requer um diretório com os repositórios dos estudantes e seus respectivos arquivos de nota.",
+	"usage.structure": "\nO diretório deve ter sido criado pelo comando 'clone' e deve conter:" +
+		"\n- Subdiretórios, cada um com o nome do repositório de um estudante (ex.: assignment-01-JoaoEstudante)." +
+		"\n- Arquivos Markdown, cada um com o nome no padrão 'grade-<nome-do-repositório>.md' (ex.: grade-assignment-01-JoaoEstudante.md).",
+	"usage.ensure": "\n\nCertifique-se de que essa estrutura seja seguida para que o comando '%s' funcione corretamente.\n",
+
+	// Keys
+	"key.back":         "voltar",
+	"key.confirm":      "confirmar",
+	"key.edit":         "editar arquivo de nota",
+	"key.nextUngraded": "próxima sem nota",
+	"key.score":        "dar nota",
+	"key.reviewed":     "marcar como revisada",
+	"key.scrollDown":   "rolar prévia",
+	"key.scrollUp":     "voltar prévia",
+	"key.quit":         "sair",
+
+	// Submissions directory
+	"dir.notExist":           "O diretório da atividade não existe. Execute o comando clone primeiro.",
+	"dir.readError":          "Falha ao ler o diretório: %v",
+	"dir.noRepositories":     "Nenhum repositório encontrado em %s",
+	"dir.noGradeFiles":       "Nenhum repositório ou arquivo de nota encontrado em %s\nConsulte a ajuda para mais informações",
+	"dir.skipped":            "%s ignorado: %s",
+	"dir.stateDir":           "diretório de estado do claro",
+	"dir.hidden":             "diretório oculto",
+	"dir.notARepository":     "não é um repositório git",
+	"dir.bare":               "repositório bare, sem diretório de trabalho para receber o pull ou o commit do arquivo de avaliação",
+	"dir.brokenGitFile":      "seu arquivo .git não aponta para um diretório git",
+	"dir.brokenGitDirectory": "seu diretório .git está incompleto",
+	"dir.noGradeFile":        "sem o arquivo de avaliação %s",
+
+	// Clone
+	"clone.fetchingClassrooms":    "buscando suas turmas",
+	"clone.retrievingAssignments": "buscando suas atividades",
+	"clone.retrievingAccepted":    "Buscando as atividades aceitas.",
+	"clone.done":                  "%d repositórios clonados",
+	"clone.selectClassroom":       "Selecione uma turma",
+	"clone.selectAssignment":      "Selecione uma atividade",
+	"clone.noClassrooms":          "Você não tem turmas no GitHub Classroom, ou não tem permissão para acessá-las.",
+	"clone.noAssignments":         "Nenhuma atividade foi encontrada para esta turma, ou você não tem permissão para acessá-las.",
+	"clone.found":                 "%d repositórios encontrados. Clonando...",
+	"clone.manifestError":         "Não foi possível salvar o manifesto da atividade: %s",
+	"clone.noSubmissions":         "Nenhuma entrega foi encontrada para esta atividade, ou você não tem permissão para acessá-las.",
+	"clone.assignmentRequired":    "A opção --assignment é obrigatória quando a saída não é 'tui'",
+
+	// Pull
+	"pull.pulling": "Atualizando %d repositórios",
+	"pull.done":    "%d repositórios atualizados",
+
+	// Push
+	"push.grading": "Enviando as avaliações",
+	"push.done":    "%d entregas avaliadas",
+
+	// Diff
+	"diff.fetchingStarter": "Obtendo o repositório de código inicial %s",
+	"diff.comparing":       "Comparando %d repositórios com o código inicial",
+	"diff.done":            "%d repositórios comparados com o código inicial. Os arquivos de patch se chamam diff-<nome-do-repositório>.patch",
+	"diff.noStarter":       "Nenhum repositório de código inicial está registrado para esta atividade. Informe um com a opção '--starter'.",
+
+	// Contributions
+	"contributions.heading":    "Contribuições",
+	"contributions.author":     "Autor",
+	"contributions.commits":    "Commits",
+	"contributions.added":      "Linhas adicionadas",
+	"contributions.removed":    "Linhas removidas",
+	"contributions.writing":    "Gerando os relatórios de contribuição de %d repositórios",
+	"contributions.authors":    "%d autores, %d commits",
+	"contributions.failed":     "Não foi possível ler os commits: %s",
+	"contributions.writeError": "Não foi possível gravar o relatório de contribuição: %s",
+	"contributions.done":       "%d relatórios de contribuição gerados",
+
+	// Grade
+	"grade.scorePrompt":   "Nota: ",
+	"grade.submissions":   "Entregas",
+	"grade.editorError":   "Erro ao executar o editor: %s",
+	"grade.writeError":    "Não foi possível escrever a nota: %s",
+	"grade.scored":        "%s recebeu nota %s",
+	"grade.header":        "Avaliadas %d/%d · revisadas %d/%d",
+	"grade.summary":       "%d de %d entregas avaliadas, %d revisadas",
+	"grade.tuiRequired":   "O comando grade é interativo e requer a saída 'tui'",
+	"grade.progressError": "Não foi possível salvar o progresso da avaliação: %s",
+	"grade.allGraded":     "Todas as entregas foram avaliadas",
+
+	// Summary
+	"summary.counts":    "%d com sucesso, %d ignorados, %d com falha",
+	"summary.skipped":   "Ignorados:",
+	"summary.failed":    "Com falha:",
+	"summary.failures":  "%d de %d repositórios falharam",
+	"summary.tuiFailed": "Não foi possível executar a interface interativa",
+
+	// Run journal
+	"journal.resuming":        "Retomando a execução interrompida: %d repositórios já estavam concluídos",
+	"journal.nothingToResume": "Nada a retomar: todos os repositórios já estavam concluídos",
+	"journal.saveError":       "Não foi possível salvar o diário de execução: %s",
+
+	// Retries
+	"retry.rateLimit": "aguardando o limite de requisições (%s)",
+	"retry.git":       "'%s' falhou, nova tentativa em %s (tentativa %d de %d)",
+
+	// Regraded since
+	"regraded.untagged": "%d repositórios não têm a tag %s",
+	"regraded.none":     "Nenhum estudante fez commits depois do commit com a tag %s",
+	"regraded.commits":  "%d commits depois de %s, o último em %s",
+
+	// Export
+	"export.written":         "Notas de %d estudantes exportadas para %s",
+	"export.unmapped":        "%d estudantes não têm no arquivo de mapeamento a matrícula ou o e-mail que os identifica e foram deixados de fora: %s",
+	"export.unmappedListed":  "%d estudantes não estão no arquivo de mapeamento: %s",
+	"export.ungraded":        "%d estudantes não têm nota no arquivo de avaliação e foram deixados de fora: %s",
+	"export.mappingTemplate": "%s foi criado com a lista de estudantes: preencha a matrícula, o email e o nome de cada um e exporte novamente",
+	"export.invalidFormat":   "formato de exportação '%s' inválido. Os formatos válidos são: %s",
+
+	// Roster
+	"roster.empty":       "Nenhum estudante encontrado em %s",
+	"roster.imported":    "%d estudantes importados para a lista da turma %s",
+	"roster.unlinked":    "%d estudantes ainda não têm login do GitHub na lista",
+	"roster.none":        "Nenhuma lista da turma encontrada. Importe uma com: claro roster import <roster.csv>",
+	"roster.noDirectory": "Nenhum diretório da turma para armazenar a lista da turma. Informe-o após o arquivo da lista ou defina o diretório raiz dos clones: claro config set cloneroot <diretório>",
+	"roster.allAccepted": "Todos os %d estudantes da lista aceitaram a tarefa e enviaram seu trabalho",
+	"roster.missing":     "%d dos %d estudantes da lista não têm entrega para avaliar",
+	"absent.noLogin":     "Nenhuma conta do GitHub vinculada à lista",
+	"absent.notAccepted": "Nunca aceitou a tarefa",
+	"absent.noCommits":   "Nenhum commit além do código inicial",
+	"absent.zeroGrades":  "Nota zero atribuída a %d estudantes",
+
+	// Profiles
+	"profile.staleDefault": "O perfil padrão '%s' não existe mais; usando o arquivo de configuração principal. Escolha outro com 'claro config profile use <nome>'",
+	"profile.notFound":     "perfil '%s' não encontrado. Crie-o com 'claro config profile create %s'",
+	"profile.invalidName":  "nome de perfil inválido '%s'. Use apenas letras, dígitos, '-' e '_'",
+	"profile.exists":       "o perfil '%s' já existe",
+	"profile.created":      "Perfil '%s' criado em %s",
+	"profile.createdHint":  "Use 'claro --profile %s config' para configurá-lo e 'claro --profile %s token add' para armazenar seu token",
+	"profile.defaultSet":   "O perfil '%s' agora é o perfil padrão",
+	"profile.none":         "Nenhum perfil encontrado. Crie um com 'claro config profile create <nome>'",
+	"profile.active":       "(ativo)",
+
+	// Config file and migration
+	"configFile.notFound":       "O arquivo de configuração %s não existe",
+	"configFile.created":        "Arquivo de configuração %s criado",
+	"configFile.readError":      "Não foi possível ler o arquivo de configuração: %s",
+	"migrate.failed":            "Não foi possível migrar o arquivo de configuração %s: %s",
+	"migrate.migrated":          "O arquivo de configuração %s foi migrado para a versão %d:",
+	"migrate.change":            "v%d → v%d: %s",
+	"migrate.added":             "chave '%s' adicionada com '%v'",
+	"migrate.invalidVersion":    "versão de configuração inválida '%v'",
+	"migrate.newerVersion":      "a versão %d do arquivo de configuração foi escrita por um claro mais novo (este suporta até a versão %d)",
+	"migrate.unsupportedFormat": "formato de configuração não suportado '%s'. Os formatos suportados são: %s",
+	"migrate.exists":            "%s já existe",
+	"migrate.converted":         "%s convertido para %s",
+	"migrate.backup":            "arquivo de configuração anterior salvo como %s",
+	"migrate.upToDate":          "O arquivo de configuração %s está atualizado (versão %d)",
+
+	// Logs
+	"logs.none": "Nenhum log encontrado em %s",
+
+	// Git
+	"git.mkdirError":           "Erro ao criar o diretório: %s",
+	"git.cloneError":           "Erro '%s' ao clonar: %s",
+	"git.rubricError":          "Não foi possível ler o arquivo de rubrica: %s",
+	"git.gradeFileCreate":      "Não foi possível criar o arquivo de nota: %s",
+	"git.gradeFileWrite":       "Não foi possível escrever no arquivo markdown: %s",
+	"git.alreadyExists":        "O repositório já existe, clone ignorado",
+	"git.newCommits":           "novos commits",
+	"git.nothingToCommit":      "nada para enviar, diretório de trabalho limpo",
+	"git.gradeFileCopy":        "Erro ao copiar o arquivo de nota: %s",
+	"git.starterError":         "Erro '%s' ao obter o repositório de código inicial: %s",
+	"git.starterFetchFailed":   "Não foi possível obter o repositório de código inicial",
+	"git.diffFailed":           "Falha ao executar 'git diff'",
+	"git.patchWriteError":      "Não foi possível escrever o arquivo de patch: %s",
+	"git.noChangesFromStarter": "nenhuma alteração em relação ao código inicial",
+	"git.repaired":             "clone parcial reparado",
+	"git.zeroGradeReplaced":    "substituída a nota zero atribuída antes de a tarefa ser aceita",
+	"git.repairError":          "Não foi possível remover o clone parcial: %s",
+	"git.commitFailed":         "Não foi possível fazer o commit do arquivo de avaliação: %s",
+	"git.sshSigningKey":        "A assinatura de commits com SSH precisa do arquivo da chave na configuração signingkey",
+	"git.messageTemplate":      "Modelo de mensagem de commit inválido: %s",
+	"git.gradedCommitNotFound": "o commit avaliado %s não foi encontrado no repositório",
+	"git.tagFailed":            "Não foi possível marcar o commit avaliado com a tag %s: %s",
+	"git.errAuth":              "git %s: o GitHub rejeitou as credenciais",
+	"git.errNonFastForward":    "git %s rejeitado: o repositório remoto tem commits que o local não tem (non-fast-forward)",
+	"git.errNotFound":          "git %s: repositório não encontrado",
+	"git.errDiverged":          "git %s: o repositório local tem commits que o remoto não tem, como um commit de nota que não foi enviado, e o backend git nativo não consegue fazer o rebase deles. Execute o comando novamente com --git-backend=exec",
+	"git.errFailed":            "git %s falhou: %s",
+	"git.required":             "o comando %s precisa do 'git' instalado e no PATH do usuário, mesmo com o backend git nativo",
+
+	// Feedback delivery
+	"feedback.unknownDelivery":          "Modo de entrega da avaliação desconhecido: %s",
+	"feedback.findPRFailed":             "Não foi possível encontrar o pull request Feedback",
+	"feedback.noPullRequest":            "nenhum pull request Feedback encontrado",
+	"feedback.originUnreadable":         "não foi possível ler o remoto origin",
+	"feedback.notGitHub":                "o remoto origin não é um repositório do GitHub: %s",
+	"feedback.postFailed":               "Falha ao publicar a avaliação no pull request #%d",
+	"feedback.reviewPosted":             "revisão publicada no pull request #%d",
+	"feedback.reviewUpdated":            "revisão atualizada no pull request #%d",
+	"feedback.reviewManifest":           "Revisão entregue no pull request #%d, mas não foi possível registrá-la no manifesto: %s",
+	"feedback.commentPosted":            "comentário publicado no pull request #%d",
+	"feedback.commentUpdated":           "comentário atualizado no pull request #%d",
+	"feedback.commentManifest":          "Comentário entregue no pull request #%d, mas não foi possível registrá-lo no manifesto: %s",
+	"feedback.issueFailed":              "Falha ao entregar a issue de avaliação",
+	"feedback.issueCreated":             "issue #%d criada",
+	"feedback.issueUpdated":             "issue #%d atualizada",
+	"feedback.issueManifest":            "Issue #%d entregue, mas não foi possível registrá-la no manifesto: %s",
+	"feedback.annotations":              "Anotações",
+	"feedback.annotationFileNotFound":   "@%s:%d: arquivo não encontrado no commit %.7s",
+	"feedback.annotationLineOutOfRange": "@%s:%d: linha fora do intervalo, o arquivo tem %d linhas",
+	"feedback.invalidAnnotations":       "anotações inválidas em %s:",
+
+	// GitHub REST API
+	"rest.classroomsFailed":  "Falha ao obter a lista de turmas",
+	"rest.assignmentsFailed": "Falha ao obter a lista de atividades",
+	"rest.assignmentFailed":  "Falha ao obter a atividade",
+	"rest.acceptedFailed":    "Falha ao obter a lista de atividades aceitas",
+	"rest.clientError":       "Ocorreu um erro ao obter o cliente da API REST do GitHub. %s",
+	"rest.badCredentials": "%s => HTTP %d: %s." +
+		"\nAcesse https://docs.github.com/pt/authentication/keeping-your-account-and-data-secure/managing-your-personal-access-tokens para gerar um novo PAT" +
+		"\nDepois, execute '%s' para atualizar seu GitHub Personal Access Token.",
+
+	// Token
+	"token.storeError":      "Não foi possível armazenar o token no chaveiro do sistema operacional:\n => %s",
+	"token.stored":          "Seu GitHub Personal Access Token foi armazenado com sucesso no chaveiro do sistema operacional!",
+	"token.prompt":          "Informe seu GitHub Personal Access Token (classic):",
+	"token.noTerminal":      "Sem terminal para solicitar o GitHub Personal Access Token: defina-o na variável de ambiente %s, ou armazene-o com 'claro token add'",
+	"token.missing":         "Nenhum GitHub Personal Access Token foi informado",
+	"token.confirmDelete":   "Tem certeza de que deseja remover o GitHub Personal Access Token do chaveiro do sistema operacional?",
+	"token.secretNotFound":  "Segredo não encontrado no chaveiro do sistema operacional.",
+	"token.deleteError":     "Erro ao remover o token do chaveiro do sistema operacional: %s",
+	"token.deleted":         "Token removido do chaveiro do sistema operacional",
+	"token.nothingToDelete": "Nenhum token encontrado no chaveiro do sistema operacional. Nada a remover.",
+	"token.confirmOverride": "Já existe um GitHub Personal Access Token do claro no chaveiro do sistema operacional. Deseja substituí-lo?",
+
+	// Config menu
+	"config.menu":              "Qual opção você deseja configurar?",
+	"config.filename":          "Nome do arquivo de nota",
+	"config.message":           "Mensagem de commit",
+	"config.title":             "Título do arquivo de nota",
+	"config.grade":             "Texto da nota no arquivo de nota",
+	"config.delivery":          "Entrega da avaliação",
+	"config.label":             "Rótulo da issue de avaliação",
+	"config.rubric":            "Rubrica do arquivo de nota",
+	"config.host":              "Host da API do GitHub",
+	"config.cloneRoot":         "Diretório padrão para clonar",
+	"config.language":          "Idioma",
+	"config.quit":              "Sair",
+	"config.runError":          "Ocorreu um erro ao executar o programa:",
+	"config.filenameHelp":      "O nome do arquivo com a avaliação que será criado no repositório do estudante.",
+	"config.messageHelp":       "Mensagem de commit da avaliação. Pode usar os campos {{.Student}}, {{.Name}}, {{.Repository}}, {{.Grade}}, {{.Assignment}}, {{.Commit}} e {{.Grader}}",
+	"config.titleHelp":         "O título do arquivo que representa a folha de avaliação",
+	"config.gradeHelp":         "O texto da nota inserido no arquivo que representa a folha de avaliação.",
+	"config.deliveryHelp":      "Como a avaliação é entregue aos estudantes",
+	"config.deliveryCommit":    "Fazer commit do arquivo de nota no repositório do estudante",
+	"config.deliveryReview":    "Publicar o arquivo de nota como revisão no pull request Feedback",
+	"config.deliveryComment":   "Publicar o arquivo de nota como comentário no pull request Feedback",
+	"config.deliveryIssue":     "Criar ou atualizar uma issue com o título do arquivo de nota",
+	"config.labelHelp":         "O rótulo aplicado à issue quando a avaliação é entregue como issue",
+	"config.rubricHelp":        "Caminho de um arquivo Markdown cujo conteúdo é inserido em cada novo arquivo de nota (deixe vazio para nenhum)",
+	"config.hostHelp":          "O host do GitHub usado para acessar a API do GitHub Classroom (ex.: github.com)",
+	"config.cloneRootHelp":     "O diretório onde as atividades são clonadas (deixe vazio para o diretório atual)",
+	"config.languageHelp":      "O idioma das mensagens do claro",
+	"config.languageAutomatic": "Automático (a partir da variável de ambiente LANG)",
+}
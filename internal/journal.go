@@ -0,0 +1,181 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/github/gh-classroom/pkg/classroom"
+)
+
+const journalFilename = "journal.json"
+
+// journalInProgress is the state of a repository whose action started but didn't finish, because the
+// run was interrupted
+const journalInProgress = "in_progress"
+
+// journal records the state of each repository in the last run of the clone and push commands, so the
+// next run knows where an interrupted run stopped. It is stored in the submissions directory.
+type journal struct {
+	Runs      map[string]*journalRun `json:"runs"`
+	directory string
+}
+
+type journalRun struct {
+	Started      time.Time               `json:"started"`
+	Finished     bool                    `json:"finished"`
+	Repositories map[string]journalEntry `json:"repositories"`
+}
+
+type journalEntry struct {
+	Status  string    `json:"status"`
+	Error   string    `json:"error,omitempty"`
+	Updated time.Time `json:"updated"`
+}
+
+// openJournal reads the journal stored in the submissions directory. A missing or unreadable journal
+// is replaced by an empty one.
+func openJournal(submissionsDirectory string) *journal {
+	j := &journal{directory: submissionsDirectory}
+	if data, err := os.ReadFile(filepath.Join(stateDir(submissionsDirectory), journalFilename)); err == nil {
+		_ = json.Unmarshal(data, j)
+	}
+	if j.Runs == nil {
+		j.Runs = make(map[string]*journalRun)
+	}
+	return j
+}
+
+// begin starts a run of the action. When resuming, the states recorded by the previous run are kept,
+// so the repositories it finished can be left out. Otherwise a new run is started, but the repositories
+// the previous run left in progress stay marked as interrupted until they are processed again.
+func (j *journal) begin(action string, resume bool) error {
+	previous := j.Runs[action]
+	if resume && previous != nil {
+		previous.Finished = false
+		return j.save()
+	}
+	run := &journalRun{Started: time.Now(), Repositories: make(map[string]journalEntry)}
+	if previous != nil {
+		for name, entry := range previous.Repositories {
+			if entry.Status == journalInProgress {
+				run.Repositories[name] = entry
+			}
+		}
+	}
+	j.Runs[action] = run
+	return j.save()
+}
+
+// done reports whether the action already succeeded, or was skipped, on the repository in the current run
+func (j *journal) done(action string, repository string) bool {
+	status := j.status(action, repository)
+	return status == StatusSucceeded || status == StatusSkipped
+}
+
+// interrupted reports whether the action started on the repository but didn't finish
+func (j *journal) interrupted(action string, repository string) bool {
+	return j.status(action, repository) == journalInProgress
+}
+
+func (j *journal) status(action string, repository string) string {
+	if run := j.Runs[action]; run != nil {
+		return run.Repositories[repository].Status
+	}
+	return ""
+}
+
+// start records that the action started on the repository. The journal is saved on a best-effort basis:
+// failing to save it must not stop the action.
+func (j *journal) start(action string, repository string) {
+	j.set(action, repository, journalEntry{Status: journalInProgress})
+}
+
+// record records the result of the action on a repository
+func (j *journal) record(action string, r Result) {
+	j.set(action, r.Repository, journalEntry{Status: r.Status, Error: r.Error})
+}
+
+// finish records that the run of the action processed all the repositories
+func (j *journal) finish(action string) {
+	if run := j.Runs[action]; run != nil {
+		run.Finished = true
+		_ = j.save()
+	}
+}
+
+func (j *journal) set(action string, repository string, entry journalEntry) {
+	run := j.Runs[action]
+	if run == nil {
+		return
+	}
+	entry.Updated = time.Now()
+	run.Repositories[repository] = entry
+	_ = j.save()
+}
+
+// save writes the journal to a temporary file that replaces the journal, so an interruption while
+// saving doesn't leave a truncated journal behind
+func (j *journal) save() error {
+	if err := os.MkdirAll(stateDir(j.directory), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	filename := filepath.Join(stateDir(j.directory), journalFilename)
+	if err = os.WriteFile(filename+".tmp", data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(filename+".tmp", filename)
+}
+
+// clone returns the step that clones the accepted assignment, recording when the clone starts. A clone
+// the journal records as interrupted is repaired.
+func (j *journal) clone(a classroom.AcceptedAssignment) step {
+	return gitCloneAssignment(a, j.interrupted(actionClone, a.Repository.Name), func() {
+		j.start(actionClone, a.Repository.Name)
+	})
+}
+
+// pendingAssignments returns the accepted assignments whose repositories weren't cloned yet in the current run
+func pendingAssignments(j *journal, accepted []classroom.AcceptedAssignment) []classroom.AcceptedAssignment {
+	var pending []classroom.AcceptedAssignment
+	for _, a := range accepted {
+		if !j.done(actionClone, a.Repository.Name) {
+			pending = append(pending, a)
+		}
+	}
+	return pending
+}
+
+// pendingRepositories returns the repositories the action wasn't performed on yet in the current run
+func pendingRepositories(j *journal, action string, repositories []os.DirEntry) []os.DirEntry {
+	var pending []os.DirEntry
+	for _, entry := range repositories {
+		if !j.done(action, entry.Name()) {
+			pending = append(pending, entry)
+		}
+	}
+	return pending
+}
+
+// resumeMessage returns the message that tells how many repositories were left out because the
+// interrupted run already processed them, if any
+func resumeMessage(total int, pending int) string {
+	switch {
+	case total > 0 && pending == 0:
+		return i18n.T("journal.nothingToResume")
+	case pending < total:
+		return i18n.T("journal.resuming", total-pending)
+	}
+	return ""
+}
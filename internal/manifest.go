@@ -0,0 +1,186 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/github/gh-classroom/pkg/classroom"
+	"github.com/spf13/viper"
+)
+
+const (
+	stateDirName     = ".claro"
+	manifestFilename = "manifest.json"
+)
+
+// manifest records what claro knows about the assignment behind a submissions directory.
+// It is written by the clone command and read by the commands that work on a submissions directory.
+type manifest struct {
+	Assignment   manifestAssignment   `json:"assignment"`
+	Repositories []manifestRepository `json:"repositories"`
+}
+
+type manifestAssignment struct {
+	Id          int    `json:"id"`
+	Slug        string `json:"slug"`
+	Title       string `json:"title"`
+	StarterCode string `json:"starter_code,omitempty"`
+	// Type is "individual" or "group"
+	Type string `json:"type,omitempty"`
+	// GradeLabels are the grade strings the grade files were written with, so their grades are still
+	// found after the grade setting or the language changes
+	GradeLabels []string `json:"grade_labels,omitempty"`
+}
+
+type manifestRepository struct {
+	Name     string `json:"name"`
+	FullName string `json:"full_name"`
+	Url      string `json:"url"`
+	// Students holds the GitHub logins of the student, or of all the team members in group assignments
+	Students []string `json:"students,omitempty"`
+	// Team is the name of the team in group assignments
+	Team     string `json:"team,omitempty"`
+	Feedback string `json:"feedback_pull_request,omitempty"`
+	Issue    int    `json:"issue,omitempty"`
+	// Comment is the ID of the feedback comment posted on the Feedback pull request
+	Comment int64 `json:"feedback_comment,omitempty"`
+	// Review is the ID of the feedback review posted on the Feedback pull request
+	Review int64 `json:"feedback_review,omitempty"`
+}
+
+// newManifest creates a manifest from the assignment and its accepted assignments returned by the GitHub Classroom API
+func newManifest(a classroom.Assignment, accepted []classroom.AcceptedAssignment) manifest {
+	var m manifest
+	m.Assignment = manifestAssignment{Id: a.Id, Slug: a.Slug, Title: a.Title, StarterCode: a.StarterCodeRepository.HtmlUrl, Type: a.AssignmentType}
+	for _, r := range accepted {
+		entry := manifestRepository{
+			Name:     r.Repository.Name,
+			FullName: r.Repository.FullName,
+			Url:      r.Repository.HtmlUrl,
+			Students: studentLogins(r),
+			Feedback: r.FeedbackPullRequestUrl,
+		}
+		if groupAssignment(r) {
+			// GitHub Classroom names the repositories of group assignments <assignment-slug>-<team-name>
+			entry.Team = strings.TrimPrefix(r.Repository.Name, a.Slug+"-")
+		}
+		m.Repositories = append(m.Repositories, entry)
+	}
+	return m
+}
+
+// mergeManifest returns the manifest built from the GitHub Classroom API merged into the manifest
+// recorded before: the assignment and the repositories are updated, while what claro recorded for each
+// repository, such as its feedback issue, is kept. Repositories missing from the new manifest are kept too.
+func mergeManifest(recorded manifest, fetched manifest) manifest {
+	merged := manifest{Assignment: fetched.Assignment, Repositories: recorded.Repositories}
+	for _, label := range recorded.Assignment.GradeLabels {
+		merged.Assignment = merged.Assignment.withGradeLabel(label)
+	}
+	for _, entry := range fetched.Repositories {
+		if previous, ok := recorded.repository(entry.Name); ok {
+			entry.Issue = previous.Issue
+			entry.Comment = previous.Comment
+			entry.Review = previous.Review
+		}
+		merged = merged.withRepository(entry)
+	}
+	return merged
+}
+
+// updateManifest records the assignment and its accepted assignments in the manifest of the submissions
+// directory, keeping what claro recorded for each repository in previous runs
+func updateManifest(submissionsDirectory string, a classroom.Assignment, accepted []classroom.AcceptedAssignment) error {
+	recorded, _ := loadManifest(submissionsDirectory)
+	m := mergeManifest(recorded, newManifest(a, accepted))
+	// The grade files created by the clone are written with the current grade string
+	m.Assignment = m.Assignment.withGradeLabel(viper.GetString("grade"))
+	return saveManifest(submissionsDirectory, m)
+}
+
+// withGradeLabel returns the assignment with the grade string recorded, unless it already was
+func (a manifestAssignment) withGradeLabel(label string) manifestAssignment {
+	label = strings.TrimSpace(label)
+	if label != "" && !slices.Contains(a.GradeLabels, label) {
+		a.GradeLabels = append(slices.Clone(a.GradeLabels), label)
+	}
+	return a
+}
+
+// recordGradeLabel records the current grade string in the manifest of the submissions directory, if it
+// has one
+func recordGradeLabel(submissionsDirectory string) error {
+	m, err := loadManifest(submissionsDirectory)
+	if err != nil {
+		return nil
+	}
+	assignment := m.Assignment.withGradeLabel(viper.GetString("grade"))
+	if len(assignment.GradeLabels) == len(m.Assignment.GradeLabels) {
+		return nil
+	}
+	m.Assignment = assignment
+	return saveManifest(submissionsDirectory, m)
+}
+
+// groupAssignment reports whether the accepted assignment belongs to a team
+func groupAssignment(a classroom.AcceptedAssignment) bool {
+	return a.Assignment.AssignmentType == "group" || len(a.Students) > 1
+}
+
+// stateDir returns the directory where claro keeps its state for a submissions directory
+func stateDir(submissionsDirectory string) string {
+	return filepath.Join(submissionsDirectory, stateDirName)
+}
+
+// loadManifest reads the manifest stored in the submissions directory
+func loadManifest(submissionsDirectory string) (manifest, error) {
+	var m manifest
+	data, err := os.ReadFile(filepath.Join(stateDir(submissionsDirectory), manifestFilename))
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(data, &m)
+	return m, err
+}
+
+// saveManifest writes the manifest to the submissions directory
+func saveManifest(submissionsDirectory string, m manifest) error {
+	if err := os.MkdirAll(stateDir(submissionsDirectory), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(stateDir(submissionsDirectory), manifestFilename), data, 0644)
+}
+
+// repository returns the manifest entry of a repository
+func (m manifest) repository(name string) (manifestRepository, bool) {
+	for _, r := range m.Repositories {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return manifestRepository{}, false
+}
+
+// withRepository returns the manifest with the entry of a repository replaced, or added if it is not recorded yet
+func (m manifest) withRepository(entry manifestRepository) manifest {
+	for i, r := range m.Repositories {
+		if r.Name == entry.Name {
+			m.Repositories[i] = entry
+			return m
+		}
+	}
+	m.Repositories = append(m.Repositories, entry)
+	return m
+}
@@ -0,0 +1,103 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+// commitMessageData holds the fields of the commit message template, such as
+// "{{.Assignment}} graded: {{.Grade}}"
+type commitMessageData struct {
+	// Student is the GitHub login of the student, or the logins of the team separated by commas
+	Student string
+	// Name holds the real names of the students, from the course roster
+	Name       string
+	Repository string
+	// Grade is the grade written in the grade file
+	Grade string
+	// Assignment is the assignment's title recorded by the clone command
+	Assignment string
+	// Commit is the abbreviated hash of the graded commit
+	Commit string
+	// Grader is the name of the grader
+	Grader string
+}
+
+// newCommitMessageData returns the fields of the commit message of the repository in the directory,
+// graded in the grade file
+func newCommitMessageData(directory string, gradeFile string, result Result, f feedback) commitMessageData {
+	d := commitMessageData{Student: result.Student, Name: result.Name, Repository: result.Repository, Commit: f.gradedCommit, Grader: graderName()}
+	d.Grade, _ = readGradeValue(gradeFile)
+	m, _ := loadManifest(filepath.Dir(directory))
+	d.Assignment = m.Assignment.Title
+	if d.Assignment == "" {
+		d.Assignment = m.Assignment.Slug
+	}
+	if d.Commit == "" {
+		d.Commit, _, _ = gitBackend.Head(directory)
+	}
+	if len(d.Commit) > 7 {
+		d.Commit = d.Commit[:7]
+	}
+	return d
+}
+
+// graderName returns the name of the grader, from the grader setting or else from the commit author
+func graderName() string {
+	if grader := viper.GetString("grader"); grader != "" {
+		name, _, _ := strings.Cut(grader, " <")
+		return name
+	}
+	return viper.GetString("authorname")
+}
+
+// commitMessage returns the message of the grading commit, executing the message template with the
+// data. When a grader is configured, a Co-authored-by trailer names them, so students and auditors see
+// who wrote the feedback.
+func commitMessage(data commitMessageData) (string, error) {
+	t, err := parseMessageTemplate(viper.GetString("message"))
+	if err != nil {
+		return "", err
+	}
+	var message bytes.Buffer
+	if err = t.Execute(&message, data); err != nil {
+		return "", errors.New(i18n.T("git.messageTemplate", err))
+	}
+	s := strings.TrimSpace(message.String())
+	if grader := viper.GetString("grader"); grader != "" {
+		s += "\n\nCo-authored-by: " + grader
+	}
+	return s, nil
+}
+
+func parseMessageTemplate(message string) (*template.Template, error) {
+	t, err := template.New("message").Option("missingkey=error").Parse(message)
+	if err != nil {
+		return nil, errors.New(i18n.T("git.messageTemplate", err))
+	}
+	return t, nil
+}
+
+// checkMessageTemplate returns an error if the commit message template can't be parsed or uses fields
+// that don't exist
+func checkMessageTemplate(message string) error {
+	t, err := parseMessageTemplate(message)
+	if err != nil {
+		return err
+	}
+	if err = t.Execute(&bytes.Buffer{}, commitMessageData{}); err != nil {
+		return errors.New(i18n.T("git.messageTemplate", err))
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestCheckMessageTemplate(t *testing.T) {
+	tests := map[string]bool{
+		"Graded":                             true,
+		"":                                   true,
+		"{{.Assignment}} graded: {{.Grade}}": true,
+		"{{.Student}} {{.Name}} {{.Repository}} {{.Commit}} {{.Grader}}": true,
+		"{{if .Grade}}Grade: {{.Grade}}{{else}}Graded{{end}}":            true,
+		"{{.Grade | printf \"%q\"}}":                                     true,
+		"{{.Score}} points":                                              false,
+		"{{.grade}}":                                                     false,
+		"{{.Grade":                                                       false,
+		"{{end}}":                                                        false,
+		"{{template \"other\"}}":                                         false,
+	}
+	for message, valid := range tests {
+		if err := checkMessageTemplate(message); (err == nil) != valid {
+			t.Errorf("checkMessageTemplate(%q) = %v, want valid %v", message, err, valid)
+		}
+	}
+}
+
+func TestCommitMessage(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	data := commitMessageData{Student: "alice", Name: "Alice Lima", Repository: "hw-alice", Grade: "8.5/10", Assignment: "Homework", Commit: "1a2b3c4", Grader: "Ana"}
+	tests := []struct {
+		message, grader string
+		want            string
+	}{
+		{"Graded", "", "Graded"},
+		{"{{.Assignment}} graded: {{.Grade}}", "", "Homework graded: 8.5/10"},
+		{"  {{.Repository}} ({{.Name}})\n\n", "", "hw-alice (Alice Lima)"},
+		{"Graded {{.Commit}}", "Ana Silva <ana@example.edu>", "Graded 1a2b3c4\n\nCo-authored-by: Ana Silva <ana@example.edu>"},
+	}
+	for _, tt := range tests {
+		viper.Set("message", tt.message)
+		viper.Set("grader", tt.grader)
+		if got, err := commitMessage(data); err != nil || got != tt.want {
+			t.Errorf("commitMessage(%q) = %q, %v, want %q", tt.message, got, err, tt.want)
+		}
+	}
+
+	viper.Set("message", "{{.Score}}")
+	if got, err := commitMessage(data); err == nil {
+		t.Errorf("commitMessage with an unknown field = %q, want an error", got)
+	}
+}
+
+func TestGraderName(t *testing.T) {
+	t.Cleanup(viper.Reset)
+	tests := []struct {
+		grader, authorName string
+		want               string
+	}{
+		{"Ana Silva <ana@example.edu>", "Claro Bot", "Ana Silva"},
+		{"", "Claro Bot", "Claro Bot"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		viper.Set("grader", tt.grader)
+		viper.Set("authorname", tt.authorName)
+		if got := graderName(); got != tt.want {
+			t.Errorf("graderName() with grader %q and author %q = %q, want %q", tt.grader, tt.authorName, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/cast"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ConfigVersion is the version of the config file schema written by this version of claro
+const ConfigVersion = 2
+
+// configFormats are the config file formats supported by claro, in lookup order
+var configFormats = []string{"env", "yaml", "yml", "toml"}
+
+// configMigration upgrades the settings stored in a config file from one schema version to the next.
+// It returns a description of each change made.
+type configMigration func(settings map[string]any) []string
+
+// configMigrations holds the migration from version i+1 to version i+2
+var configMigrations = []configMigration{
+	// Version 2 added the feedback delivery, the per-assignment rubric and the profile's keys
+	func(settings map[string]any) []string {
+		defaults := map[string]any{
+			"delivery":  ClaroConfigStrings.Delivery,
+			"label":     ClaroConfigStrings.Label,
+			"rubric":    ClaroConfigStrings.Rubric,
+			"host":      ClaroConfigStrings.Host,
+			"keyring":   ClaroConfigStrings.Keyring,
+			"cloneroot": ClaroConfigStrings.CloneRoot,
+		}
+		var changes []string
+		for _, key := range []string{"delivery", "label", "rubric", "host", "keyring", "cloneroot"} {
+			if _, ok := settings[key]; !ok {
+				settings[key] = defaults[key]
+				changes = append(changes, i18n.T("migrate.added", key, defaults[key]))
+			}
+		}
+		return changes
+	},
+}
+
+// FindConfigFile returns the config file with the given name, in any supported format, in the directory.
+// If there is none, the path of a new env config file is returned.
+func FindConfigFile(directory string, name string) string {
+	for _, ext := range configFormats {
+		path := filepath.Join(directory, name+"."+ext)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return filepath.Join(directory, name+".env")
+}
+
+// backupConfigFile copies the config file to a timestamped backup file and returns the backup's path
+func backupConfigFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	backup := fmt.Sprintf("%s.%s.bak", path, time.Now().Format("20060102150405"))
+	for n := 1; ; n++ {
+		if _, err = os.Stat(backup); os.IsNotExist(err) {
+			break
+		}
+		backup = fmt.Sprintf("%s.%s-%d.bak", path, time.Now().Format("20060102150405"), n)
+	}
+	return backup, os.WriteFile(backup, data, 0600)
+}
+
+// migrateSettings applies the migrations needed to bring the settings to the current schema version
+func migrateSettings(settings map[string]any) (changes []string, err error) {
+	version := 1
+	if v, ok := settings["version"]; ok {
+		if version, err = cast.ToIntE(v); err != nil {
+			return nil, errors.New(i18n.T("migrate.invalidVersion", v))
+		}
+	}
+	if version > ConfigVersion {
+		return nil, errors.New(i18n.T("migrate.newerVersion", version, ConfigVersion))
+	}
+	for ; version < ConfigVersion; version++ {
+		for _, change := range configMigrations[version-1](settings) {
+			changes = append(changes, i18n.T("migrate.change", version, version+1, change))
+		}
+	}
+	settings["version"] = ConfigVersion
+	return changes, nil
+}
+
+// MigrateConfig upgrades the config file in use to the current schema version, backing up the previous
+// file. It returns a description of each change made, which is empty if the file was already up to date.
+func MigrateConfig() ([]string, error) {
+	file, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	if file.IsSet("version") && file.GetInt("version") == ConfigVersion {
+		return nil, nil
+	}
+	settings := file.AllSettings()
+	changes, err := migrateSettings(settings)
+	if err != nil {
+		return nil, err
+	}
+	backup, err := backupConfigFile(configFilePath())
+	if err != nil {
+		return nil, err
+	}
+	if err = writeConfigFile(settings); err != nil {
+		return nil, err
+	}
+	viper.Set("version", ConfigVersion)
+	return append(changes, i18n.T("migrate.backup", backup)), nil
+}
+
+// ConfigMigrateCmd upgrades the config file to the current schema version and, optionally, converts it to another format
+func ConfigMigrateCmd(cmd *cobra.Command, args []string) error {
+	format, _ := cmd.Flags().GetString("format")
+	changes, err := MigrateConfig()
+	if err != nil {
+		return err
+	}
+	path := configFilePath()
+	current := strings.TrimPrefix(filepath.Ext(path), ".")
+	if format != "" && format != current {
+		if !slices.Contains(configFormats, format) {
+			return errors.New(i18n.T("migrate.unsupportedFormat", format, strings.Join(configFormats, ", ")))
+		}
+		file, err := readConfigFile()
+		if err != nil {
+			return err
+		}
+		newPath := strings.TrimSuffix(path, filepath.Ext(path)) + "." + format
+		if _, err = os.Stat(newPath); err == nil {
+			return errors.New(i18n.T("migrate.exists", newPath))
+		}
+		if err = file.WriteConfigAs(newPath); err != nil {
+			return err
+		}
+		backup, err := backupConfigFile(path)
+		if err != nil {
+			return err
+		}
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+		changes = append(changes, i18n.T("migrate.converted", path, newPath), i18n.T("migrate.backup", backup))
+	}
+	if len(changes) == 0 {
+		fmt.Println(i18n.T("migrate.upToDate", path, ConfigVersion))
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+	return nil
+}
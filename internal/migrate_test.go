@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateSettings(t *testing.T) {
+	tests := map[string]struct {
+		settings map[string]any
+		changes  int
+		wantErr  bool
+	}{
+		"version 1 without version key": {settings: map[string]any{"grade": "Nota: "}, changes: 6},
+		"version 1 with some keys":      {settings: map[string]any{"version": "1", "delivery": "issue", "host": "github.example.edu"}, changes: 4},
+		"current version":               {settings: map[string]any{"version": ConfigVersion}, changes: 0},
+		"current version as a string":   {settings: map[string]any{"version": "2"}, changes: 0},
+		"newer version":                 {settings: map[string]any{"version": ConfigVersion + 1}, wantErr: true},
+		"invalid version":               {settings: map[string]any{"version": "two"}, wantErr: true},
+	}
+	for name, tt := range tests {
+		changes, err := migrateSettings(tt.settings)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: migrateSettings() error = %v, want error %v", name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if len(changes) != tt.changes {
+			t.Errorf("%s: changes = %q, want %d", name, changes, tt.changes)
+		}
+		if tt.settings["version"] != ConfigVersion {
+			t.Errorf("%s: version = %v, want %d", name, tt.settings["version"], ConfigVersion)
+		}
+	}
+}
+
+func TestMigrateSettingsKeepsValues(t *testing.T) {
+	settings := map[string]any{"delivery": "issue", "grade": "Nota: "}
+	changes, err := migrateSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings["delivery"] != "issue" || settings["grade"] != "Nota: " {
+		t.Errorf("settings = %v, want the configured values kept", settings)
+	}
+	if settings["rubric"] != ClaroConfigStrings.Rubric || settings["cloneroot"] != ClaroConfigStrings.CloneRoot {
+		t.Errorf("settings = %v, want the missing keys added with their defaults", settings)
+	}
+	for _, change := range changes {
+		if !strings.HasPrefix(change, "v1 → v2: added '") {
+			t.Errorf("change = %q", change)
+		}
+		if strings.Contains(change, "'delivery'") {
+			t.Errorf("the configured delivery was changed: %q", change)
+		}
+	}
+}
+
+func TestFindConfigFile(t *testing.T) {
+	tests := map[string]struct {
+		files []string
+		want  string
+	}{
+		"none":            {nil, "config.env"},
+		"env":             {[]string{"config.env"}, "config.env"},
+		"yaml":            {[]string{"config.yaml"}, "config.yaml"},
+		"toml":            {[]string{"config.toml"}, "config.toml"},
+		"env before yaml": {[]string{"config.yaml", "config.env"}, "config.env"},
+		"yaml before yml": {[]string{"config.yml", "config.yaml"}, "config.yaml"},
+		"other name":      {[]string{"course.yaml"}, "config.env"},
+	}
+	for name, tt := range tests {
+		directory := t.TempDir()
+		for _, file := range tt.files {
+			writeFile(t, filepath.Join(directory, file), "")
+		}
+		if got := FindConfigFile(directory, "config"); got != filepath.Join(directory, tt.want) {
+			t.Errorf("%s: FindConfigFile() = %s, want %s", name, filepath.Base(got), tt.want)
+		}
+	}
+}
+
+func TestBackupConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.env")
+	writeFile(t, path, "GRADE=Nota: \n")
+	seen := make(map[string]bool)
+	for range 3 {
+		backup, err := backupConfigFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seen[backup] {
+			t.Fatalf("backup %s overwritten", backup)
+		}
+		seen[backup] = true
+		if content, _ := os.ReadFile(backup); string(content) != "GRADE=Nota: \n" {
+			t.Errorf("backup content = %q", content)
+		}
+	}
+	if _, err := backupConfigFile(filepath.Join(t.TempDir(), "missing.env")); err == nil {
+		t.Error("backup of a missing config file succeeded")
+	}
+}
@@ -0,0 +1,250 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+	"github.com/github/gh-classroom/pkg/classroom"
+	"golang.org/x/term"
+)
+
+// Actions performed on the students' repositories
+const (
+	actionClone = "clone"
+	actionPull  = "pull"
+	actionPush  = "push"
+	actionDiff  = "diff"
+)
+
+// Status of an action performed on a repository
+const (
+	StatusSucceeded = "succeeded"
+	StatusSkipped   = "skipped"
+	StatusFailed    = "failed"
+)
+
+// Result is the outcome of an action performed on a student's repository. It is produced by the core
+// clone, pull, push and diff logic and rendered by the TUI, text or JSON front-ends.
+type Result struct {
+	Repository string
+	Student    string
+	// Name holds the real names of the students, from the course roster
+	Name   string
+	Action string
+	Status string
+	// Detail is an additional note about a successful action, such as "new commits"
+	Detail   string
+	Error    string
+	Duration time.Duration
+	// authFailure is set when the action failed because GitHub rejected the user's credentials
+	authFailure bool
+}
+
+func (r Result) succeeded(detail string) Result {
+	r.Status, r.Detail = StatusSucceeded, detail
+	return r
+}
+
+func (r Result) skipped(reason string) Result {
+	r.Status, r.Error = StatusSkipped, reason
+	return r
+}
+
+func (r Result) failed(reason string) Result {
+	r.Status, r.Error = StatusFailed, reason
+	return r
+}
+
+func (r Result) failedWith(err error) Result {
+	r = r.failed(err.Error())
+	r.authFailure = isAuthError(err)
+	return r
+}
+
+// String renders the result as a line of the progress output
+func (r Result) String() string {
+	repository := r.Repository
+	if r.Name != "" {
+		repository += " (" + r.Name + ")"
+	}
+	switch r.Status {
+	case StatusSucceeded:
+		if r.Detail == "" {
+			return tui.CheckMark.String() + " " + repository
+		}
+		return tui.CheckMark.String() + " " + repository + " " + tui.DetailStyle.Render(r.Detail)
+	default:
+		return tui.ErrorMark.String() + " " + repository + " " + tui.ReasonStyle.Render(r.Error)
+	}
+}
+
+// step is the work done on one repository. Git operations that reach a remote repository are run as
+// the step's operation and finish turns their outcome into the result. Steps without an operation do
+// all their work in finish.
+type step struct {
+	operation gitOperation
+	finish    func(err error) Result
+	started   time.Time
+}
+
+func newStep(operation gitOperation, finish func(err error) Result) step {
+	return step{operation: operation, finish: finish, started: time.Now()}
+}
+
+// doneStep returns a step whose result is already known
+func doneStep(r Result) step {
+	return newStep(gitOperation{}, func(error) Result { return r })
+}
+
+func (s step) result(err error) Result {
+	if s.operation.done != nil {
+		s.operation.done()
+	}
+	r := s.finish(err)
+	r.Duration = time.Since(s.started)
+	if r.Status == StatusFailed && isAuthError(err) {
+		r.authFailure = true
+	}
+	logResult(r)
+	return r
+}
+
+// run runs the step without the TUI. The process output goes to stderr, so it doesn't mix with the
+// results written to stdout.
+func (s step) run() Result {
+	var err error
+	if s.operation.process != nil {
+		p := &process{Cmd: s.operation.process}
+		p.SetStdin(os.Stdin)
+		p.SetStdout(os.Stderr)
+		p.SetStderr(os.Stderr)
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			// Nobody can answer git's credential prompts, so git fails instead of waiting for them
+			if p.Env == nil {
+				p.Env = os.Environ()
+			}
+			p.Env = append(p.Env, "GIT_TERMINAL_PROMPT=0")
+		}
+		err = execGitError(s.operation.name, p.Run(), p.stderr.String())
+	} else if s.operation.run != nil {
+		err = s.operation.run()
+	}
+	return s.result(err)
+}
+
+// cmd returns the tea.Cmd that runs the step in the TUI, which is suspended while a git process runs
+func (s step) cmd() tea.Cmd {
+	if s.operation.process != nil {
+		p := &process{Cmd: s.operation.process}
+		return tea.Exec(p, func(err error) tea.Msg {
+			return s.result(execGitError(s.operation.name, err, p.stderr.String()))
+		})
+	}
+	return func() tea.Msg {
+		var err error
+		if s.operation.run != nil {
+			err = s.operation.run()
+		}
+		return s.result(err)
+	}
+}
+
+// gitAuthFailurePattern matches the messages git prints when the remote rejects the user's credentials
+var gitAuthFailurePattern = regexp.MustCompile(`(?i)authentication failed|could not read (username|password)|terminal prompts disabled|permission denied \(publickey|access denied|returned error: 403`)
+
+// gitTransientFailurePattern matches the messages git prints when it fails for a reason that may go
+// away, such as a dropped connection or an overloaded server
+var gitTransientFailurePattern = regexp.MustCompile(`(?i)could not resolve host|connection (timed out|reset|refused)|operation timed out|early eof|rpc failed|unexpected disconnect|returned error: (429|5\d\d)|internal server error|secondary rate limit`)
+
+// process is a step's git process. It implements tea.ExecCommand and keeps a copy of what the
+// process writes to stderr, so the reason git failed can be told.
+type process struct {
+	*exec.Cmd
+	stderr bytes.Buffer
+}
+
+// Run runs the process, running it again after a transient failure as told by the default retry policy.
+// Each attempt is logged.
+func (p *process) Run() error {
+	policy := defaultRetryPolicy
+	for attempt := 1; ; attempt++ {
+		p.stderr.Reset()
+		started := time.Now()
+		err := p.Cmd.Run()
+		logGitCommand(p.Dir, p.Args[1:], time.Since(started), err, p.stderr.String())
+		if err == nil || attempt >= policy.MaxAttempts || !gitTransientFailurePattern.Match(p.stderr.Bytes()) {
+			return err
+		}
+		delay := policy.backoff(attempt)
+		_, _ = fmt.Fprintln(p.Stderr, i18n.T("retry.git", strings.Join(p.Args, " "), delay.Round(time.Second), attempt+1, policy.MaxAttempts))
+		time.Sleep(delay)
+		// An exec.Cmd can't be started twice
+		p.Cmd = &exec.Cmd{Path: p.Path, Args: p.Args, Env: p.Env, Dir: p.Dir, Stdin: p.Stdin, Stdout: p.Stdout, Stderr: p.Stderr}
+	}
+}
+
+func (p *process) SetStdin(r io.Reader) {
+	p.Stdin = r
+}
+
+func (p *process) SetStdout(w io.Writer) {
+	p.Stdout = w
+}
+
+func (p *process) SetStderr(w io.Writer) {
+	p.Stderr = io.MultiWriter(w, &p.stderr)
+}
+
+// repositoryResult returns an empty result of the action performed on the repository in the given directory.
+// The student is taken from the manifest of the submissions directory, and their name from the course roster.
+func repositoryResult(action string, directory string) Result {
+	name := filepath.Base(directory)
+	m, _ := loadManifest(filepath.Dir(directory))
+	student := ""
+	if entry, ok := m.repository(name); ok && len(entry.Students) > 0 {
+		student = strings.Join(entry.Students, ",")
+	} else if m.Assignment.Slug != "" {
+		// GitHub Classroom names the repositories <assignment-slug>-<student-login>
+		student = strings.TrimPrefix(name, m.Assignment.Slug+"-")
+	}
+	return Result{Repository: name, Student: student, Name: rosterNames(filepath.Dir(directory), strings.Split(student, ",")), Action: action}
+}
+
+// studentLogins returns the GitHub logins of the students of an accepted assignment
+func studentLogins(a classroom.AcceptedAssignment) []string {
+	var logins []string
+	for _, s := range a.Students {
+		logins = append(logins, s.Login)
+	}
+	return logins
+}
+
+// resultLine is used by the models to print a result above the progress bar
+func resultLine(r Result) tea.Cmd {
+	return tea.Printf("%s", r)
+}
+
+// skippedLines is used by the models to print why directories of the submissions directory were
+// ignored, when --verbose is set
+func skippedLines(skipped []skippedDirectory) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, message := range skippedMessages(skipped) {
+		cmds = append(cmds, tea.Printf("%s", tui.DetailStyle.Render(message)))
+	}
+	return tea.Sequence(cmds...)
+}
@@ -0,0 +1,115 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// Output modes
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputTUI  = "tui"
+)
+
+// OutputMode is the output mode in use, set from the --output flag
+var OutputMode = OutputTUI
+
+// DetectOutputMode validates the output mode given with the --output flag. When no mode is given,
+// the TUI is used only if stdout is a terminal, so claro can run under CI, pipes and nohup.
+func DetectOutputMode(mode string) (string, error) {
+	switch mode {
+	case OutputText, OutputJSON, OutputTUI:
+		return mode, nil
+	case "":
+		if term.IsTerminal(int(os.Stdout.Fd())) {
+			return OutputTUI, nil
+		}
+		return OutputText, nil
+	}
+	return "", fmt.Errorf("invalid output mode '%s'. Valid modes are: %s, %s, %s", mode, OutputText, OutputJSON, OutputTUI)
+}
+
+// MarshalJSON encodes the result as reported in the json output mode, with the duration in seconds
+func (r Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Repository string  `json:"repo"`
+		Student    string  `json:"student"`
+		Name       string  `json:"name,omitempty"`
+		Action     string  `json:"action"`
+		Status     string  `json:"status"`
+		Detail     string  `json:"detail,omitempty"`
+		Error      string  `json:"error,omitempty"`
+		Duration   float64 `json:"duration"`
+	}{r.Repository, r.Student, r.Name, r.Action, r.Status, r.Detail, r.Error, r.Duration.Seconds()})
+}
+
+// Reporter is the front-end used when claro runs without the TUI
+type Reporter interface {
+	// Progress reports what is being done
+	Progress(message string)
+	// Report reports the result of an action performed on a repository
+	Report(r Result)
+	// Done reports that all the repositories were processed, with the summary of the results
+	Done(message string, s Summary)
+}
+
+// NewReporter returns the reporter of the output mode in use, writing to w
+func NewReporter(w io.Writer) Reporter {
+	if OutputMode == OutputJSON {
+		return jsonReporter{encoder: json.NewEncoder(w)}
+	}
+	return textReporter{w: w}
+}
+
+// textReporter writes one line per repository
+type textReporter struct {
+	w io.Writer
+}
+
+func (t textReporter) Progress(message string) {
+	_, _ = fmt.Fprintln(t.w, message)
+}
+
+func (t textReporter) Report(r Result) {
+	_, _ = fmt.Fprintln(t.w, r)
+}
+
+func (t textReporter) Done(message string, s Summary) {
+	_, _ = fmt.Fprintln(t.w, message)
+	if summary := s.String(); summary != "" {
+		_, _ = fmt.Fprintln(t.w, summary)
+	}
+}
+
+// jsonReporter writes one JSON object per repository, as JSON lines
+type jsonReporter struct {
+	encoder *json.Encoder
+}
+
+func (j jsonReporter) Progress(string) {}
+
+func (j jsonReporter) Report(r Result) {
+	_ = j.encoder.Encode(r)
+}
+
+// Done writes the summary as the last JSON line
+func (j jsonReporter) Done(_ string, s Summary) {
+	type counts struct {
+		Succeeded int `json:"succeeded"`
+		Skipped   int `json:"skipped"`
+		Failed    int `json:"failed"`
+	}
+	_ = j.encoder.Encode(struct {
+		Summary counts `json:"summary"`
+	}{counts{s.count(StatusSucceeded), s.count(StatusSkipped), s.count(StatusFailed)}})
+}
@@ -0,0 +1,139 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	profilesDirName        = "profiles"
+	defaultProfileFilename = "default-profile"
+)
+
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// ActiveProfile is the name of the profile in use, empty when the main config file is used
+var ActiveProfile string
+
+// profilesDir returns the directory where the profiles' config files are stored
+func profilesDir() string {
+	return filepath.Join(ConfigDir(), profilesDirName)
+}
+
+// profileConfigFile returns the path of a profile's config file
+func profileConfigFile(name string) string {
+	return FindConfigFile(profilesDir(), name)
+}
+
+// DefaultProfile returns the name of the profile marked as default, or an empty string if there is none
+func DefaultProfile() string {
+	data, err := os.ReadFile(filepath.Join(ConfigDir(), defaultProfileFilename))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(data))
+}
+
+// ProfileConfigFile returns the config file of a profile, or an error if the profile does not exist
+func ProfileConfigFile(name string) (string, error) {
+	path := profileConfigFile(name)
+	if _, err := os.Stat(path); err != nil {
+		return "", errors.New(i18n.T("profile.notFound", name, name))
+	}
+	return path, nil
+}
+
+// listProfiles returns the names of the existing profiles
+func listProfiles() ([]string, error) {
+	entries, err := os.ReadDir(profilesDir())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	var profiles []string
+	for _, entry := range entries {
+		ext := filepath.Ext(entry.Name())
+		if !entry.IsDir() && slices.Contains(configFormats, strings.TrimPrefix(ext, ".")) {
+			profiles = append(profiles, strings.TrimSuffix(entry.Name(), ext))
+		}
+	}
+	return profiles, nil
+}
+
+// ProfileCreateCmd creates a profile from the current configuration. The profile has its own token
+// entry in the OS keyring.
+func ProfileCreateCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !profileNamePattern.MatchString(name) {
+		return errors.New(i18n.T("profile.invalidName", name))
+	}
+	path := profileConfigFile(name)
+	if _, err := os.Stat(path); err == nil {
+		return errors.New(i18n.T("profile.exists", name))
+	}
+	if err := os.MkdirAll(profilesDir(), 0755); err != nil {
+		return err
+	}
+	v := viper.New()
+	for _, key := range configKeys() {
+		v.Set(key, viper.Get(key))
+	}
+	v.Set("keyring", configFilename+"-"+name)
+	if err := v.WriteConfigAs(path); err != nil {
+		return err
+	}
+	fmt.Println(i18n.T("profile.created", name, path))
+	fmt.Println(i18n.T("profile.createdHint", name, name))
+	return nil
+}
+
+// ProfileUseCmd marks a profile as the default one
+func ProfileUseCmd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if _, err := ProfileConfigFile(name); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(ConfigDir(), defaultProfileFilename), []byte(name+"\n"), 0644); err != nil {
+		return err
+	}
+	fmt.Println(i18n.T("profile.defaultSet", name))
+	return nil
+}
+
+// ProfileListCmd lists the existing profiles, marking the default one
+func ProfileListCmd(cmd *cobra.Command, args []string) error {
+	profiles, err := listProfiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		fmt.Println(i18n.T("profile.none"))
+		return nil
+	}
+	def := DefaultProfile()
+	for _, p := range profiles {
+		mark := " "
+		if p == def {
+			mark = "*"
+		}
+		active := ""
+		if p == ActiveProfile {
+			active = " " + i18n.T("profile.active")
+		}
+		fmt.Printf("%s %s%s\n", mark, p, active)
+	}
+	return nil
+}
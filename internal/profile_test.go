@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// useConfigDir makes a temporary directory claro's config directory, with an empty profiles directory,
+// until the test ends
+func useConfigDir(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if err := os.MkdirAll(profilesDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return ConfigDir()
+}
+
+func TestProfileNamePattern(t *testing.T) {
+	tests := map[string]bool{
+		"ifsc":          true,
+		"course-2024_1": true,
+		"SO2":           true,
+		"":              false,
+		"my profile":    false,
+		"../main":       false,
+		"a/b":           false,
+		"señor":         false,
+		"config.env":    false,
+	}
+	for name, want := range tests {
+		if got := profileNamePattern.MatchString(name); got != want {
+			t.Errorf("profileNamePattern(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
+
+func TestDefaultProfile(t *testing.T) {
+	tests := map[string]struct {
+		written bool
+		content string
+		want    string
+	}{
+		"none":         {false, "", ""},
+		"name":         {true, "ifsc", "ifsc"},
+		"with newline": {true, "ifsc\n", "ifsc"},
+		"blank":        {true, "  \n", ""},
+	}
+	for name, tt := range tests {
+		directory := useConfigDir(t)
+		if tt.written {
+			writeFile(t, filepath.Join(directory, defaultProfileFilename), tt.content)
+		}
+		if got := DefaultProfile(); got != tt.want {
+			t.Errorf("%s: DefaultProfile() = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestProfileConfigFile(t *testing.T) {
+	directory := useConfigDir(t)
+	writeFile(t, filepath.Join(directory, profilesDirName, "ifsc.yaml"), "grade: 'Nota: '\n")
+
+	if path, err := ProfileConfigFile("ifsc"); err != nil || path != filepath.Join(directory, profilesDirName, "ifsc.yaml") {
+		t.Errorf("ProfileConfigFile(ifsc) = %s, %v", path, err)
+	}
+	if path, err := ProfileConfigFile("gone"); err == nil {
+		t.Errorf("ProfileConfigFile(gone) = %s, want an error", path)
+	}
+}
+
+func TestListProfiles(t *testing.T) {
+	directory := useConfigDir(t)
+	if profiles, err := listProfiles(); err != nil || len(profiles) != 0 {
+		t.Errorf("listProfiles() without profiles = %v, %v", profiles, err)
+	}
+
+	for _, file := range []string{"ifsc.env", "course.yaml", "old.toml", "notes.txt", "ifsc.env.20240101000000.bak"} {
+		writeFile(t, filepath.Join(directory, profilesDirName, file), "")
+	}
+	if err := os.MkdirAll(filepath.Join(directory, profilesDirName, "archive.env"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	profiles, err := listProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(profiles)
+	if want := []string{"course", "ifsc", "old"}; !slices.Equal(profiles, want) {
+		t.Errorf("listProfiles() = %v, want %v", profiles, want)
+	}
+}
@@ -6,15 +6,16 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/emersonmello/claro/internal/tui"
 )
 
@@ -38,6 +39,7 @@ type PullModel struct {
 	done                 bool
 	width                int
 	height               int
+	summary              Summary
 }
 
 func NewPullModel(directory string) PullModel {
@@ -91,17 +93,17 @@ func (m PullModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func initialPullUpdate(msg tea.Msg, m PullModel) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tui.AssignmentDirError:
-		cmd := tea.Printf("%s", msg)
-		return m, tea.Sequence(cmd, tea.Quit)
-	case []os.DirEntry:
-		m.repositories = msg
+		m.summary.Err = errors.New(string(msg))
+		return m, tea.Sequence(tea.Printf("%s", tui.ErrorStyle.Render(string(msg)+"\n")), tea.Quit)
+	case submissions:
+		m.repositories = msg.repositories
 		if len(m.repositories) > 0 {
 			m.state = pullDir
 			m.index = 0
-			fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repositories[m.index].Name()))
-			return m, tea.Sequence(tea.Printf("Pulling %d repositories\n", len(m.repositories)), gitPullCmd(fullpath, m.repositories[m.index].Name()))
+			return m, tea.Sequence(skippedLines(msg.skipped), tea.Printf("%s\n", i18n.T("pull.pulling", len(m.repositories))), m.pullCurrent())
 		} else {
-			return m, tea.Sequence(tea.Printf(tui.ErrorStyle.Render(fmt.Sprintf("No repositories found in %s\n", m.submissionsDirectory))), tea.Quit)
+			m.summary.Err = errors.New(i18n.T("dir.noRepositories", m.submissionsDirectory))
+			return m, tea.Sequence(skippedLines(msg.skipped), tea.Printf("%s", tui.ErrorStyle.Render(m.summary.Err.Error()+"\n")), tea.Quit)
 		}
 
 	}
@@ -110,26 +112,18 @@ func initialPullUpdate(msg tea.Msg, m PullModel) (tea.Model, tea.Cmd) {
 
 func pullUpdate(msg tea.Msg, m PullModel) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	case tui.SuccessfullMsg, tui.SuccessfullPullMsg:
-		m.totalPulled++
-		cmd := tea.Printf("%s %s", tui.CheckMark, msg)
-		if m.index >= len(m.repositories)-1 {
-			m.done = true
-			return m, tea.Sequence(cmd, tea.Quit)
+	case Result:
+		if msg.Status == StatusSucceeded {
+			m.totalPulled++
 		}
-		m.index++
-		fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repositories[m.index].Name()))
-		return m, tea.Sequence(cmd, gitPullCmd(fullpath, m.repositories[m.index].Name()))
-	case tui.ErrorMsg:
-		reason := lipgloss.NewStyle().Foreground(lipgloss.Color("#783D38")).Italic(true).SetString(string(msg)).Render()
-		cmd := tea.Printf("%s %s %s", tui.ErrorMark, m.repositories[m.index].Name(), reason)
+		m.summary.add(msg)
+		cmd := resultLine(msg)
 		if m.index >= len(m.repositories)-1 {
 			m.done = true
 			return m, tea.Sequence(cmd, tea.Quit)
 		}
 		m.index++
-		fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repositories[m.index].Name()))
-		return m, tea.Sequence(cmd, gitPullCmd(fullpath, m.repositories[m.index].Name()))
+		return m, tea.Sequence(cmd, m.pullCurrent())
 	case progress.FrameMsg:
 		newModel, cmd := m.progress.Update(msg)
 		if newModel, ok := newModel.(progress.Model); ok {
@@ -140,6 +134,17 @@ func pullUpdate(msg tea.Msg, m PullModel) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// Summary returns the results of the pulled repositories, or the error that stopped the command
+func (m PullModel) Summary() Summary {
+	return m.summary
+}
+
+// pullCurrent returns the command that pulls the repository being processed
+func (m PullModel) pullCurrent() tea.Cmd {
+	fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repositories[m.index].Name()))
+	return gitPull(fullpath).cmd()
+}
+
 // View renders the current view of the PullModel based on its state.
 //
 // If the state is `pullDir` and repositories are available, it will display
@@ -156,12 +161,12 @@ func (m PullModel) View() string {
 
 func (m PullModel) PullView() string {
 	if m.done {
-		return tui.DoneStyle.Render(fmt.Sprintf("Pulled %d repositories\n", m.totalPulled))
+		return tui.DoneStyle.Render(i18n.T("pull.done", m.totalPulled) + "\n")
 	}
 	n := len(m.repositories)
 	w := lipgloss.Width(fmt.Sprintf("%d", n))
 	count := fmt.Sprintf(" %*d/%*d", w, m.index+1, w, n)
-	per := float64(m.index) / float64(len(m.repositories)-1)
+	per := progressPercent(m.index, n)
 	prog := m.progress.ViewAs(per)
 
 	repository := tui.CurrentRepositoryStyle.Render(m.repositories[m.index].Name())
@@ -181,23 +186,10 @@ func (m PullModel) PullView() string {
 // - tea.Cmd: a command that, when executed, returns a tea.Msg containing a list of directories.
 func getReposDirectoryList(sourceDirectory string) tea.Cmd {
 	return func() tea.Msg {
-		if strings.HasPrefix(sourceDirectory, "~") {
-			dirname, _ := os.UserHomeDir()
-			sourceDirectory = filepath.Join(dirname, sourceDirectory[1:])
-		}
-		if _, err := os.Stat(sourceDirectory); os.IsNotExist(err) {
-			return tui.AssignmentDirError(tui.ErrorStyle.Render("The assignment directory does not exist. Please run the clone command first.\n"))
-		}
-		entries, err := os.ReadDir(sourceDirectory)
+		s, err := scanSubmissions(sourceDirectory)
 		if err != nil {
-			return tui.AssignmentDirError(tui.ErrorStyle.Render(fmt.Sprintf("Failed to read the directory: %v\n", err)))
-		}
-		onlyDirs := entries[:0]
-		for _, entry := range entries {
-			if entry.IsDir() {
-				onlyDirs = append(onlyDirs, entry)
-			}
+			return tui.AssignmentDirError(err.Error())
 		}
-		return onlyDirs
+		return s
 	}
 }
@@ -6,16 +6,16 @@ Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
-	"regexp"
-	"strings"
 
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/progress"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/emersonmello/claro/internal/tui"
 )
 
@@ -34,6 +34,7 @@ type pair struct {
 type repo struct {
 	repoMap      map[string]pair
 	repositories []os.DirEntry
+	skipped      []skippedDirectory
 }
 
 type PushModel struct {
@@ -49,9 +50,14 @@ type PushModel struct {
 	done                 bool
 	width                int
 	height               int
+	resume               bool
+	journal              *journal
+	summary              Summary
 }
 
-func NewPushModel(directory string) PushModel {
+// NewPushModel creates a new PushModel. If resume is set, the repositories graded by the interrupted
+// run recorded in the journal are left out.
+func NewPushModel(directory string, resume bool) PushModel {
 	styles := tui.CreateDefaultStyles()
 	keys := tui.ClaroKeyMap()
 	h := help.New()
@@ -72,6 +78,7 @@ func NewPushModel(directory string) PushModel {
 		done:                 false,
 		width:                0,
 		height:               0,
+		resume:               resume,
 	}
 }
 
@@ -104,14 +111,28 @@ func initialPushUpdate(msg tea.Msg, m PushModel) (tea.Model, tea.Cmd) {
 		m.repos = msg
 		if len(m.repos.repoMap) > 0 {
 			m.state = pushDir
-			fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
-			return m, tea.Sequence(tea.Printf("Grading submissions\n"), gitPullCmd(fullpath, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
+			grading := tea.Sequence(skippedLines(m.repos.skipped), tea.Printf("%s\n", i18n.T("push.grading")))
+			m.journal = openJournal(expandHomeDirectory(m.submissionsDirectory))
+			if err := m.journal.begin(actionPush, m.resume); err != nil {
+				grading = tea.Sequence(grading, tea.Printf("%s", tui.ErrorStyle.Render(i18n.T("journal.saveError", err)+"\n")))
+			}
+			total := len(m.repos.repositories)
+			m.repos.repositories = pendingRepositories(m.journal, actionPush, m.repos.repositories)
+			if message := resumeMessage(total, len(m.repos.repositories)); message != "" {
+				grading = tea.Sequence(grading, tea.Printf("%s\n", message))
+			}
+			if len(m.repos.repositories) == 0 {
+				m.done = true
+				return m, tea.Sequence(grading, tea.Quit)
+			}
+			return m, tea.Sequence(grading, m.pushCurrent())
 		} else {
-			return m, tea.Sequence(tea.Printf(tui.ErrorStyle.Render(fmt.Sprintf("No repositories or grade files found in %s\nPlease see the help for more information\n", m.submissionsDirectory))), tea.Quit)
+			m.summary.Err = errors.New(i18n.T("dir.noGradeFiles", m.submissionsDirectory))
+			return m, tea.Sequence(skippedLines(m.repos.skipped), tea.Printf("%s", tui.ErrorStyle.Render(m.summary.Err.Error()+"\n")), tea.Quit)
 		}
 	case tui.AssignmentDirError:
-		cmd := tea.Printf("%s", msg)
-		return m, tea.Sequence(cmd, tea.Quit)
+		m.summary.Err = errors.New(string(msg))
+		return m, tea.Sequence(tea.Printf("%s", tui.ErrorStyle.Render(string(msg)+"\n")), tea.Quit)
 
 	}
 	return m, nil
@@ -119,35 +140,27 @@ func initialPushUpdate(msg tea.Msg, m PushModel) (tea.Model, tea.Cmd) {
 
 func pushUpdate(msg tea.Msg, m PushModel) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
-	// Handle successful push message
-	case tui.SuccessfullMsg:
-		m.totalPushed++
-		cmd := tea.Printf("%s %s ", tui.CheckMark, msg)
+	case Result:
+		// The repository is pulled before the feedback is delivered
+		if msg.Action == actionPull && msg.Status == StatusSucceeded {
+			return m, gitDeliverFeedback(m.currentDirectory(), m.repos.repoMap[m.repos.repositories[m.index].Name()]).cmd()
+		}
+		msg.Action = actionPush
+		m.journal.record(actionPush, msg)
+		if msg.Status == StatusSucceeded {
+			m.totalPushed++
+		}
+		m.summary.add(msg)
+		cmd := resultLine(msg)
 		if m.index >= len(m.repos.repositories)-1 {
 			// If all repositories have been processed, mark as done and quit
+			m.journal.finish(actionPush)
 			m.done = true
 			return m, tea.Sequence(cmd, tea.Quit)
 		}
 		// Move to the next repository
 		m.index++
-		fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
-		return m, tea.Sequence(cmd, gitPullCmd(fullpath, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
-	// Handle successful pull message
-	case tui.SuccessfullPullMsg:
-		fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
-		return m, gitCommitAndPushCmd(fullpath, m.repos.repositories[m.index].Name(), m.repos.repoMap[m.repos.repositories[m.index].Name()])
-	case tui.ErrorMsg:
-		reason := lipgloss.NewStyle().Foreground(lipgloss.Color("#783D38")).Italic(true).Render(string(msg))
-		cmd := tea.Printf("%s %s %s", tui.ErrorMark, m.repos.repositories[m.index].Name(), reason)
-		if m.index >= len(m.repos.repositories)-1 {
-			m.done = true
-			return m, tea.Sequence(cmd, tea.Quit)
-		}
-		m.index++
-		fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
-		return m, tea.Sequence(cmd, gitPullCmd(fullpath, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
-	case tui.ErrorPullMsg:
-		return m, tea.Sequence(tea.Printf(m.styles.ErrorText.Render(string(msg))), tea.Quit)
+		return m, tea.Sequence(cmd, m.pushCurrent())
 	case progress.FrameMsg:
 		newModel, cmd := m.progress.Update(msg)
 		if newModel, ok := newModel.(progress.Model); ok {
@@ -158,6 +171,24 @@ func pushUpdate(msg tea.Msg, m PushModel) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// Summary returns the results of the graded repositories, or the error that stopped the command
+func (m PushModel) Summary() Summary {
+	return m.summary
+}
+
+// pushCurrent records in the journal that the repository being processed is being graded, and returns
+// the command that pulls it before the feedback is delivered
+func (m PushModel) pushCurrent() tea.Cmd {
+	m.journal.start(actionPush, m.repos.repositories[m.index].Name())
+	return gitPull(m.currentDirectory()).cmd()
+}
+
+// currentDirectory returns the directory of the repository being processed
+func (m PushModel) currentDirectory() string {
+	fullpath, _ := filepath.Abs(filepath.Join(m.submissionsDirectory, m.repos.repoMap[m.repos.repositories[m.index].Name()].repository.Name()))
+	return fullpath
+}
+
 func (m PushModel) View() string {
 	if m.state == pushDir {
 		return m.PushView()
@@ -167,12 +198,12 @@ func (m PushModel) View() string {
 
 func (m PushModel) PushView() string {
 	if m.done {
-		return tui.DoneStyle.Render(fmt.Sprintf("Graded %d submissions\n", m.totalPushed))
+		return tui.DoneStyle.Render(i18n.T("push.done", m.totalPushed) + "\n")
 	}
 	n := len(m.repos.repositories)
 	w := lipgloss.Width(fmt.Sprintf("%d", n))
 	count := fmt.Sprintf(" %*d/%*d", w, m.index+1, w, n)
-	per := float64(m.index) / float64(len(m.repos.repositories)-1)
+	per := progressPercent(m.index, n)
 	prog := m.progress.ViewAs(per)
 
 	repository := tui.CurrentRepositoryStyle.Render(m.repos.repositories[m.index].Name())
@@ -191,62 +222,34 @@ func (m PushModel) PushView() string {
 // - tea.Cmd: a command that, when executed, returns a tea.Msg containing a repo struct with matched repositories and grade files.
 func getRepositoriesAndGradeFiles(sourceDirectory string) tea.Cmd {
 	return func() tea.Msg {
-		fileNamePattern := "^(grade-).*(\\.md)$"
-		regexpPattern, _ := regexp.Compile(fileNamePattern)
-
-		r := repo{
-			repoMap:      make(map[string]pair),
-			repositories: []os.DirEntry{},
-		}
-
-		if strings.HasPrefix(sourceDirectory, "~") {
-			dirname, _ := os.UserHomeDir()
-			sourceDirectory = filepath.Join(dirname, sourceDirectory[1:])
-		}
-		if _, err := os.Stat(sourceDirectory); os.IsNotExist(err) {
-			return tui.AssignmentDirError(tui.ErrorStyle.Render("The assignment directory does not exist. Please run the clone command first.\n"))
-
-		}
-		entries, err := os.ReadDir(sourceDirectory)
+		r, err := repositoriesAndGradeFiles(sourceDirectory)
 		if err != nil {
-			return tui.AssignmentDirError(tui.ErrorStyle.Render(fmt.Sprintf("Failed to read the directory: %v\n", err)))
-		}
-
-		regularEntries := make([]os.DirEntry, 0, len(entries))
-		directories := make([]os.DirEntry, 0, len(entries))
-		for _, entry := range entries {
-			if entry.IsDir() {
-				if !checkIfDirectoryIsAGitRepo(entry, sourceDirectory) {
-					continue
-				}
-				directories = append(directories, entry)
-			} else {
-				if info, e := entry.Info(); e == nil {
-					if info.Mode().IsRegular() {
-						regularEntries = append(regularEntries, entry)
-					}
-				}
-			}
+			return tui.AssignmentDirError(err.Error())
 		}
+		return r
+	}
+}
 
-		for _, entry := range directories {
-			for _, file := range regularEntries {
-				if regexpPattern.MatchString(file.Name()) {
-					fN := strings.Split(file.Name(), "grade-")
-					if len(fN) == 2 {
-						nN := strings.Split(fN[1], ".md")
-						if len(nN) == 2 {
-							key := nN[0]
-							if key == entry.Name() {
-								r.repoMap[key] = pair{repository: entry, gradeFilename: file}
-								r.repositories = append(r.repositories, entry)
-								break
-							}
-						}
-					}
-				}
-			}
+// repositoriesAndGradeFiles lists the repositories in the submissions directory that have a grade file.
+// The repositories without a grade file are ignored.
+func repositoriesAndGradeFiles(sourceDirectory string) (repo, error) {
+	r := repo{
+		repoMap:      make(map[string]pair),
+		repositories: []os.DirEntry{},
+	}
+	s, err := scanSubmissions(sourceDirectory)
+	if err != nil {
+		return r, err
+	}
+	r.skipped = s.skipped
+	for _, entry := range s.repositories {
+		gradeFile, ok := s.gradeFiles[entry.Name()]
+		if !ok {
+			r.skipped = append(r.skipped, skippedDirectory{name: entry.Name(), reason: i18n.T("dir.noGradeFile", gradeFilename(entry.Name()))})
+			continue
 		}
-		return r
+		r.repoMap[entry.Name()] = pair{repository: entry, gradeFilename: gradeFile}
+		r.repositories = append(r.repositories, entry)
 	}
+	return r, nil
 }
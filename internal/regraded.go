@@ -0,0 +1,122 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+// gradeTagPrefix starts the name of the tags marking the graded commits
+const gradeTagPrefix = "graded/"
+
+// gradeTagName returns the name of the tag marking the graded commit of the assignment in the
+// submissions directory, graded/<assignment-slug>
+func gradeTagName(submissionsDirectory string) string {
+	m, _ := loadManifest(submissionsDirectory)
+	slug := m.Assignment.Slug
+	if slug == "" {
+		slug = strings.TrimSuffix(filepath.Base(submissionsDirectory), "-submissions")
+	}
+	return gradeTagPrefix + slug
+}
+
+// tagGradedCommit tags the commit recorded in the grade file's header, or HEAD if the header has none
+func tagGradedCommit(directory string, tag string, graded string, message string) error {
+	if graded == "" {
+		graded = "HEAD"
+	}
+	commit, err := gitBackend.ResolveCommit(directory, graded)
+	if err != nil {
+		return errors.New(i18n.T("git.gradedCommitNotFound", graded))
+	}
+	return gitBackend.Tag(directory, tag, commit, message, configuredCommitOptions())
+}
+
+// regrade is a student's repository with commits made after its graded commit
+type regrade struct {
+	Repository string `json:"repo"`
+	Student    string `json:"student"`
+	Name       string `json:"name,omitempty"`
+	// Graded is the abbreviated hash of the graded commit
+	Graded  string `json:"graded"`
+	Commits int    `json:"commits"`
+	// LastCommit is the date of the student's last commit
+	LastCommit string `json:"last_commit"`
+}
+
+// RegradedSince writes the students' repositories in the submissions directory whose HEAD moved past
+// the graded commit tagged by the push command. Commits that only change the grading file are left
+// out, so the grading commit itself doesn't count.
+func RegradedSince(directory string, w io.Writer) error {
+	directory, _ = filepath.Abs(expandHomeDirectory(directory))
+	s, err := scanSubmissions(directory)
+	if err != nil {
+		return err
+	}
+	tag := gradeTagName(directory)
+	var regrades []regrade
+	untagged := 0
+	for _, entry := range s.repositories {
+		fullpath := filepath.Join(directory, entry.Name())
+		graded, err := gitRunner.Output(fullpath, "rev-parse", "--verify", "-q", "--short", "refs/tags/"+tag+"^{commit}")
+		if err != nil {
+			untagged++
+			continue
+		}
+		out, err := gitRunner.Output(fullpath, "log", "--format=%ci", "refs/tags/"+tag+"..HEAD", "--", ".", ":(exclude)"+viper.GetString("filename"))
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+		if strings.TrimSpace(string(out)) == "" {
+			continue
+		}
+		// The dates of the student's commits, from the newest
+		dates := strings.Split(strings.TrimSpace(string(out)), "\n")
+		result := repositoryResult("", fullpath)
+		regrades = append(regrades, regrade{
+			Repository: entry.Name(),
+			Student:    result.Student,
+			Name:       result.Name,
+			Graded:     strings.TrimSpace(string(graded)),
+			Commits:    len(dates),
+			LastCommit: dates[0],
+		})
+	}
+	if OutputMode == OutputJSON {
+		encoder := json.NewEncoder(w)
+		for _, r := range regrades {
+			if err = encoder.Encode(r); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if untagged > 0 {
+		_, _ = fmt.Fprintln(w, i18n.T("regraded.untagged", untagged, tag))
+	}
+	if len(regrades) == 0 {
+		_, _ = fmt.Fprintln(w, i18n.T("regraded.none", tag))
+		return nil
+	}
+	t := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, r := range regrades {
+		student := r.Student
+		if r.Name != "" {
+			student = r.Name
+		}
+		_, _ = fmt.Fprintf(t, "%s\t%s\t%s\n", r.Repository, student, i18n.T("regraded.commits", r.Commits, r.Graded, r.LastCommit))
+	}
+	return t.Flush()
+}
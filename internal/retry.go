@@ -0,0 +1,153 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/emersonmello/claro/internal/tui"
+)
+
+// retryPolicy tells how often and how long to wait before retrying a GitHub REST API request or a git
+// command that failed for a reason that may go away, such as a 502 or a dropped connection
+type retryPolicy struct {
+	// MaxAttempts is the number of attempts, including the first one
+	MaxAttempts int
+	// BaseDelay is the delay before the first retry, doubled at each retry up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// MaxWait is the longest wait for a rate limit to reset. Requests that would wait longer fail.
+	MaxWait time.Duration
+}
+
+var defaultRetryPolicy = retryPolicy{
+	MaxAttempts: 4,
+	BaseDelay:   time.Second,
+	MaxDelay:    30 * time.Second,
+	MaxWait:     15 * time.Minute,
+}
+
+// backoff returns the delay before the given retry (1 for the first retry). Half of the delay is
+// random, so the repositories processed at the same time don't retry in lockstep.
+func (p retryPolicy) backoff(retry int) time.Duration {
+	d := p.BaseDelay
+	for i := 1; i < retry && d < p.MaxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, p.MaxDelay)
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+// retryNotice shows that claro is waiting before a retry. It writes to stderr, unless RunTUI replaces
+// it to print the notice above the TUI.
+var retryNotice = func(message string) {
+	_, _ = fmt.Fprintln(os.Stderr, message)
+}
+
+// retryTransport is a http.RoundTripper that retries the requests to the GitHub REST API that failed
+// with a transient error, a 5xx gateway error or a rate limit. Requests that change something, like
+// posting a review, are retried only when rejected by a rate limit, as GitHub may have processed them
+// before a gateway error.
+type retryTransport struct {
+	base   http.RoundTripper
+	policy retryPolicy
+	sleep  func(time.Duration)
+}
+
+func newRetryTransport(base http.RoundTripper) *retryTransport {
+	return &retryTransport{base: base, policy: defaultRetryPolicy, sleep: time.Sleep}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		started := time.Now()
+		resp, err := t.base.RoundTrip(req)
+		logRequest(req, resp, err, time.Since(started))
+		if attempt >= t.policy.MaxAttempts {
+			return resp, err
+		}
+		delay, rateLimited, retry := t.delay(req, resp, err, attempt)
+		if !retry || (req.Body != nil && req.GetBody == nil) {
+			return resp, err
+		}
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			_ = resp.Body.Close()
+		}
+		if rateLimited {
+			retryNotice(tui.BowtieMark.String() + " " + i18n.T("retry.rateLimit", delay.Round(time.Second)))
+		}
+		t.sleep(delay)
+		if req.GetBody != nil {
+			body, e := req.GetBody()
+			if e != nil {
+				return nil, e
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// delay returns how long to wait before retrying the request, whether the wait is for a rate limit
+// to reset, and whether the request should be retried at all
+func (t *retryTransport) delay(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, bool, bool) {
+	idempotent := req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodPut || req.Method == http.MethodDelete
+	if err != nil {
+		var certificateError *tls.CertificateVerificationError
+		if errors.Is(err, context.Canceled) || errors.As(err, &certificateError) || !idempotent {
+			return 0, false, false
+		}
+		return t.policy.backoff(attempt), false, true
+	}
+	if wait, ok := rateLimitWait(resp, time.Now()); ok {
+		if wait > t.policy.MaxWait {
+			return 0, false, false
+		}
+		return wait, true, true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return t.policy.backoff(attempt), true, true
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return t.policy.backoff(attempt), false, idempotent
+	}
+	return 0, false, false
+}
+
+// rateLimitWait returns how long GitHub asks to wait before retrying a request rejected by a rate
+// limit: the Retry-After header of secondary rate limits, or the reset time of the primary rate limit
+// once no requests remain
+func rateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if seconds, err := strconv.Atoi(after); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if date, err := http.ParseTime(after); err == nil {
+			return max(date.Sub(now), 0), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			// The reset time has a resolution of seconds, so one more second is waited
+			return max(time.Unix(reset, 0).Sub(now), 0) + time.Second, true
+		}
+	}
+	return 0, false
+}
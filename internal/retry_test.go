@@ -0,0 +1,206 @@
+package internal
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stubServer answers each request with the next of the given handlers, repeating the last one
+func stubServer(t *testing.T, handlers ...http.HandlerFunc) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(hits.Add(1))
+		handlers[min(n, len(handlers))-1](w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func status(code int, headers ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.WriteHeader(code)
+	}
+}
+
+// testClient returns a client whose retries are recorded instead of slept
+func testClient(t *testing.T) (*http.Client, *[]time.Duration) {
+	t.Helper()
+	var waits []time.Duration
+	transport := newRetryTransport(http.DefaultTransport)
+	transport.sleep = func(d time.Duration) { waits = append(waits, d) }
+	notice := retryNotice
+	retryNotice = func(string) {}
+	t.Cleanup(func() { retryNotice = notice })
+	return &http.Client{Transport: transport}, &waits
+}
+
+func TestRetryGatewayErrors(t *testing.T) {
+	server, hits := stubServer(t, status(http.StatusBadGateway), status(http.StatusServiceUnavailable), status(http.StatusOK))
+	client, waits := testClient(t)
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if hits.Load() != 3 {
+		t.Errorf("requests = %d, want 3", hits.Load())
+	}
+	for i, w := range *waits {
+		limit := defaultRetryPolicy.BaseDelay << i
+		if w < limit/2 || w > limit {
+			t.Errorf("wait %d = %s, want between %s and %s", i, w, limit/2, limit)
+		}
+	}
+}
+
+func TestRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	server, hits := stubServer(t, status(http.StatusGatewayTimeout))
+	client, _ := testClient(t)
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusGatewayTimeout)
+	}
+	if int(hits.Load()) != defaultRetryPolicy.MaxAttempts {
+		t.Errorf("requests = %d, want %d", hits.Load(), defaultRetryPolicy.MaxAttempts)
+	}
+}
+
+func TestRetryHonorsRetryAfter(t *testing.T) {
+	server, hits := stubServer(t, status(http.StatusForbidden, "Retry-After", "7"), status(http.StatusOK))
+	client, waits := testClient(t)
+	var notices []string
+	retryNotice = func(message string) { notices = append(notices, message) }
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if hits.Load() != 2 {
+		t.Errorf("requests = %d, want 2", hits.Load())
+	}
+	if len(*waits) != 1 || (*waits)[0] != 7*time.Second {
+		t.Errorf("waits = %v, want [7s]", *waits)
+	}
+	if len(notices) != 1 || !strings.Contains(notices[0], "waiting for rate limit") {
+		t.Errorf("notices = %q, want a rate limit notice", notices)
+	}
+}
+
+func TestRetryHonorsRateLimitReset(t *testing.T) {
+	reset := strconv.FormatInt(time.Now().Add(20*time.Second).Unix(), 10)
+	server, _ := stubServer(t, status(http.StatusForbidden, "X-RateLimit-Remaining", "0", "X-RateLimit-Reset", reset), status(http.StatusOK))
+	client, waits := testClient(t)
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if len(*waits) != 1 || (*waits)[0] < 19*time.Second || (*waits)[0] > 22*time.Second {
+		t.Errorf("waits = %v, want about 21s", *waits)
+	}
+}
+
+func TestRetryDoesNotWaitBeyondMaxWait(t *testing.T) {
+	server, hits := stubServer(t, status(http.StatusForbidden, "Retry-After", "3600"))
+	client, _ := testClient(t)
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if hits.Load() != 1 {
+		t.Errorf("requests = %d, want 1", hits.Load())
+	}
+}
+
+func TestRetryForbiddenWithoutRateLimit(t *testing.T) {
+	server, hits := stubServer(t, status(http.StatusForbidden))
+	client, _ := testClient(t)
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if hits.Load() != 1 {
+		t.Errorf("requests = %d, want 1", hits.Load())
+	}
+}
+
+func TestRetryPostOnlyOnRateLimit(t *testing.T) {
+	bodies := make(chan string, 4)
+	record := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies <- string(body)
+			next(w, r)
+		}
+	}
+
+	server, hits := stubServer(t, record(status(http.StatusBadGateway)))
+	client, _ := testClient(t)
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"body":"grade"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if hits.Load() != 1 {
+		t.Errorf("requests after a 502 = %d, want 1", hits.Load())
+	}
+	<-bodies
+
+	server, hits = stubServer(t, record(status(http.StatusTooManyRequests, "Retry-After", "1")), record(status(http.StatusCreated)))
+	resp, err = client.Post(server.URL, "application/json", strings.NewReader(`{"body":"grade"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || hits.Load() != 2 {
+		t.Errorf("status = %d after %d requests, want %d after 2", resp.StatusCode, hits.Load(), http.StatusCreated)
+	}
+	for i := 0; i < 2; i++ {
+		if body := <-bodies; body != `{"body":"grade"}` {
+			t.Errorf("body of request %d = %q, want the original body", i+1, body)
+		}
+	}
+}
+
+func TestRetryTransportErrors(t *testing.T) {
+	server, hits := stubServer(t, func(w http.ResponseWriter, r *http.Request) {
+		// Drop the connection without answering
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		_ = conn.Close()
+	}, status(http.StatusOK))
+	client, waits := testClient(t)
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if hits.Load() != 2 || len(*waits) != 1 {
+		t.Errorf("requests = %d with %d waits, want 2 with 1 wait", hits.Load(), len(*waits))
+	}
+}
@@ -0,0 +1,188 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+	"github.com/spf13/viper"
+)
+
+// rosterColumns maps the header of the roster files to the student's fields. Besides the columns of
+// claro's student mapping file, the roster exported by GitHub Classroom is read: its identifier is the
+// student's ID and github_username their login.
+var rosterColumns = map[string]string{
+	"login":           "login",
+	"github_username": "login",
+	"github":          "login",
+	"id":              "id",
+	"identifier":      "id",
+	"student_id":      "id",
+	"email":           "email",
+	"name":            "name",
+}
+
+// readStudents reads a CSV file listing students, such as a student mapping file or a roster. Its header
+// names the columns, in any order; only the login column is required. Students without a login are the
+// ones who haven't linked a GitHub account to the roster yet.
+func readStudents(path string) ([]student, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func(f *os.File) {
+		_ = f.Close()
+	}(f)
+	reader := csv.NewReader(f)
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		if field, ok := rosterColumns[strings.ToLower(strings.TrimSpace(name))]; ok {
+			columns[field] = i
+		}
+	}
+	if _, ok := columns["login"]; !ok {
+		return nil, fmt.Errorf("%s: the header has no 'login' column", path)
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	var students []student
+	for _, record := range records[1:] {
+		s := student{Login: field(record, "login"), ID: field(record, "id"), Email: field(record, "email"), Name: field(record, "name")}
+		// GitHub Classroom rosters are often identified by the students' email
+		if s.Email == "" && strings.Contains(s.ID, "@") {
+			s.Email = s.ID
+		}
+		if s != (student{}) {
+			students = append(students, s)
+		}
+	}
+	return students, nil
+}
+
+// writeStudents writes the students to a CSV file in the format of the student mapping file
+func writeStudents(path string, students []student) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	c := csv.NewWriter(f)
+	_ = c.Write([]string{"login", "id", "email", "name"})
+	for _, s := range students {
+		_ = c.Write([]string{s.Login, s.ID, s.Email, s.Name})
+	}
+	c.Flush()
+	if err = c.Error(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// courseRosterPath returns where the roster command imports the course roster: the student mapping file
+// of the course directory, which is the clone root unless given. It returns "" if neither is set.
+func courseRosterPath(courseDirectory string) string {
+	if courseDirectory == "" {
+		courseDirectory = viper.GetString("cloneroot")
+	}
+	if courseDirectory == "" {
+		return ""
+	}
+	path, _ := filepath.Abs(filepath.Join(expandHomeDirectory(courseDirectory), studentMappingFilename))
+	return path
+}
+
+// rosterStudents returns the students of the submissions directory's roster with the logins, leaving out
+// the logins missing from the roster
+func rosterStudents(submissionsDirectory string, logins []string) []student {
+	mapping, err := loadStudentMapping(findStudentMapping(submissionsDirectory))
+	if err != nil {
+		return nil
+	}
+	var students []student
+	for _, login := range logins {
+		if s, ok := mapping[strings.ToLower(login)]; ok && s.Name != "" {
+			students = append(students, s)
+		}
+	}
+	return students
+}
+
+// rosterNames returns the real names of the students with the logins, separated by commas, or "" if
+// none of them is in the submissions directory's roster
+func rosterNames(submissionsDirectory string, logins []string) string {
+	var names []string
+	for _, s := range rosterStudents(submissionsDirectory, logins) {
+		names = append(names, s.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+// studentIdentities returns the names of the students followed by their ID, separated by commas, such
+// as "Ana Lima (2024001)"
+func studentIdentities(students []student) string {
+	var identities []string
+	for _, s := range students {
+		if s.ID != "" {
+			identities = append(identities, fmt.Sprintf("%s (%s)", s.Name, s.ID))
+		} else {
+			identities = append(identities, s.Name)
+		}
+	}
+	return strings.Join(identities, ", ")
+}
+
+// ImportRoster stores the students of the roster file as the roster of the course directory, or of the
+// clone root if it is empty, replacing the roster imported before. The roster file is a CSV file exported
+// by GitHub Classroom, or one in the format of the student mapping file.
+func ImportRoster(rosterFile string, courseDirectory string, w io.Writer) error {
+	path := courseRosterPath(courseDirectory)
+	if path == "" {
+		return errors.New(i18n.T("roster.noDirectory"))
+	}
+	students, err := readStudents(expandHomeDirectory(rosterFile))
+	if err != nil {
+		return err
+	}
+	if len(students) == 0 {
+		return errors.New(i18n.T("roster.empty", rosterFile))
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	if err = writeStudents(path, students); err != nil {
+		return err
+	}
+	_, _ = fmt.Fprintln(w, i18n.T("roster.imported", len(students), path))
+	unlinked := 0
+	for _, s := range students {
+		if s.Login == "" {
+			unlinked++
+		}
+	}
+	if unlinked > 0 {
+		_, _ = fmt.Fprintln(w, i18n.T("roster.unlinked", unlinked))
+	}
+	return nil
+}
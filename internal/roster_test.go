@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadStudents(t *testing.T) {
+	tests := map[string]struct {
+		content string
+		want    []student
+	}{
+		"mapping file": {"login,id,email,name\nana,2024001,ana@example.edu,Ana Lima\n",
+			[]student{{Login: "ana", ID: "2024001", Email: "ana@example.edu", Name: "Ana Lima"}}},
+		"classroom roster": {"identifier,github_username,github_id,name\n2024001,ana,101,Ana Lima\n2024002,,,Bruno Souza\n",
+			[]student{{Login: "ana", ID: "2024001", Name: "Ana Lima"}, {ID: "2024002", Name: "Bruno Souza"}}},
+		"email as identifier": {"identifier,github_username\nana@example.edu,ana\n",
+			[]student{{Login: "ana", ID: "ana@example.edu", Email: "ana@example.edu"}}},
+		"columns in any order": {" Name , EMAIL,Login\nAna Lima,ana@example.edu,ana\n",
+			[]student{{Login: "ana", Email: "ana@example.edu", Name: "Ana Lima"}}},
+		"blank and short rows": {"login,id,email,name\n,,,\nana\n bruno , 2024002\n",
+			[]student{{Login: "ana"}, {Login: "bruno", ID: "2024002"}}},
+		"header only": {"login,id,email,name\n", nil},
+		"empty file":  {"", nil},
+	}
+	for name, tt := range tests {
+		path := filepath.Join(t.TempDir(), "students.csv")
+		writeFile(t, path, tt.content)
+		got, err := readStudents(path)
+		if err != nil || !slices.Equal(got, tt.want) {
+			t.Errorf("%s: readStudents() = %+v, %v, want %+v", name, got, err, tt.want)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "students.csv")
+	writeFile(t, path, "id,email,name\n2024001,ana@example.edu,Ana Lima\n")
+	if _, err := readStudents(path); err == nil {
+		t.Error("readStudents accepted a header without a login column")
+	}
+	if _, err := readStudents(filepath.Join(t.TempDir(), "missing.csv")); err == nil {
+		t.Error("readStudents of a missing file succeeded")
+	}
+}
+
+func TestWriteStudents(t *testing.T) {
+	students := []student{
+		{Login: "ana", ID: "2024001", Email: "ana@example.edu", Name: "Ana Lima"},
+		{ID: "2024002", Name: "Souza, Bruno"},
+	}
+	path := filepath.Join(t.TempDir(), "students.csv")
+	if err := writeStudents(path, students); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := readStudents(path); err != nil || !slices.Equal(got, students) {
+		t.Errorf("readStudents() of the written file = %+v, %v, want %+v", got, err, students)
+	}
+}
+
+func TestStudentIdentities(t *testing.T) {
+	students := []student{{Name: "Ana Lima", ID: "2024001"}, {Name: "Bruno Souza"}}
+	if got, want := studentIdentities(students), "Ana Lima (2024001), Bruno Souza"; got != want {
+		t.Errorf("studentIdentities() = %q, want %q", got, want)
+	}
+	if got := studentIdentities(nil); got != "" {
+		t.Errorf("studentIdentities(nil) = %q", got)
+	}
+}
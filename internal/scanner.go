@@ -0,0 +1,155 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/emersonmello/claro/internal/i18n"
+)
+
+// Verbose is set by the --verbose flag to explain why directories of the submissions directory were ignored
+var Verbose bool
+
+// submissions is what was found in a submissions directory: the students' repositories, their grade
+// files and the directories that were ignored, with the reason why
+type submissions struct {
+	repositories []os.DirEntry
+	// gradeFiles holds the grade file of each repository, by repository name
+	gradeFiles map[string]os.DirEntry
+	skipped    []skippedDirectory
+}
+
+// skippedDirectory is a directory of the submissions directory that is not a student's repository
+type skippedDirectory struct {
+	name   string
+	reason string
+}
+
+// scanSubmissions lists the students' repositories and grade files in the submissions directory. It only
+// reads the file system, without running git or changing the working directory, so it is safe to call
+// concurrently. Regular clones, linked worktrees and submodules are repositories; bare repositories
+// and hidden directories are ignored.
+func scanSubmissions(sourceDirectory string) (submissions, error) {
+	s := submissions{gradeFiles: make(map[string]os.DirEntry)}
+	sourceDirectory = expandHomeDirectory(sourceDirectory)
+	if _, err := os.Stat(sourceDirectory); os.IsNotExist(err) {
+		return s, errors.New(i18n.T("dir.notExist"))
+	}
+	entries, err := os.ReadDir(sourceDirectory)
+	if err != nil {
+		return s, errors.New(i18n.T("dir.readError", err))
+	}
+	for _, entry := range entries {
+		path := filepath.Join(sourceDirectory, entry.Name())
+		// Following symbolic links, so a link to a repository is a repository
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			if name, ok := gradeFileRepository(entry.Name()); ok && info.Mode().IsRegular() {
+				s.gradeFiles[name] = entry
+			}
+			continue
+		}
+		switch {
+		case entry.Name() == stateDirName:
+			s.skip(entry.Name(), i18n.T("dir.stateDir"))
+		case strings.HasPrefix(entry.Name(), "."):
+			s.skip(entry.Name(), i18n.T("dir.hidden"))
+		default:
+			if reason := notARepository(path); reason != "" {
+				s.skip(entry.Name(), reason)
+			} else {
+				s.repositories = append(s.repositories, entry)
+			}
+		}
+	}
+	return s, nil
+}
+
+func (s *submissions) skip(name string, reason string) {
+	s.skipped = append(s.skipped, skippedDirectory{name: name, reason: reason})
+}
+
+// gradeFileRepository returns the name of the repository of a grade file, named grade-<repository>.md
+func gradeFileRepository(filename string) (string, bool) {
+	name, ok := strings.CutPrefix(filename, "grade-")
+	if !ok {
+		return "", false
+	}
+	name, ok = strings.CutSuffix(name, ".md")
+	return name, ok && name != ""
+}
+
+// notARepository returns why the directory is not the work tree of a git repository, or "" if it is.
+// The work tree of a regular clone has a .git directory, while linked worktrees and submodules have a
+// .git file pointing to their git directory, in the main repository or in the superproject.
+func notARepository(path string) string {
+	dotGit := filepath.Join(path, ".git")
+	info, err := os.Stat(dotGit)
+	switch {
+	case err == nil && info.IsDir():
+		if !isGitDirectory(dotGit) {
+			return i18n.T("dir.brokenGitDirectory")
+		}
+		return ""
+	case err == nil:
+		if gitDir, ok := readGitFile(dotGit); !ok || !isGitDirectory(gitDir) {
+			return i18n.T("dir.brokenGitFile")
+		}
+		return ""
+	case isGitDirectory(path):
+		return i18n.T("dir.bare")
+	}
+	return i18n.T("dir.notARepository")
+}
+
+// readGitFile returns the git directory a .git file points to, written as "gitdir: <path>"
+func readGitFile(dotGit string) (string, bool) {
+	content, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", false
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(content)), "gitdir:")
+	if !ok {
+		return "", false
+	}
+	gitDir = strings.TrimSpace(gitDir)
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(filepath.Dir(dotGit), gitDir)
+	}
+	return gitDir, true
+}
+
+// isGitDirectory reports whether the directory looks like a git directory: it has a HEAD file and
+// either the objects directory or, for linked worktrees, a commondir file pointing to the main one
+func isGitDirectory(path string) bool {
+	if info, err := os.Stat(filepath.Join(path, "HEAD")); err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if info, err := os.Stat(filepath.Join(path, "objects")); err == nil && info.IsDir() {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(path, "commondir"))
+	return err == nil
+}
+
+// skippedMessages returns the lines explaining why directories were ignored, when --verbose is set
+func skippedMessages(skipped []skippedDirectory) []string {
+	if !Verbose {
+		return nil
+	}
+	messages := make([]string, 0, len(skipped))
+	for _, d := range skipped {
+		messages = append(messages, i18n.T("dir.skipped", d.name, d.reason))
+	}
+	return messages
+}
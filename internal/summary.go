@@ -0,0 +1,154 @@
+// Package internal
+package internal
+
+/*
+Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
+*/
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/emersonmello/claro/internal/i18n"
+)
+
+// Exit codes, so wrapper scripts can react to the outcome of a command
+const (
+	ExitSuccess = 0
+	// ExitError is used for usage and configuration errors, and for errors that stop a command before
+	// any repository is processed
+	ExitError          = 1
+	ExitPartialFailure = 2
+	ExitTotalFailure   = 3
+	ExitAuthFailure    = 4
+)
+
+// ExitCodeError is an error with the exit code that reports it
+type ExitCodeError struct {
+	Code int
+	Err  error
+	// Reported is set when the error was already shown to the user
+	Reported bool
+}
+
+func (e *ExitCodeError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitCodeError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode returns the exit code that reports the error returned by a command
+func ExitCode(err error) int {
+	var e *ExitCodeError
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.As(err, &e):
+		return e.Code
+	case isAuthError(err):
+		return ExitAuthFailure
+	}
+	return ExitError
+}
+
+// Summary collects the results of the actions performed on the students' repositories
+type Summary struct {
+	Results []Result
+	// Err is the error that stopped the command, if any
+	Err error
+}
+
+func (s *Summary) add(r Result) {
+	s.Results = append(s.Results, r)
+}
+
+func (s Summary) count(status string) int {
+	n := 0
+	for _, r := range s.Results {
+		if r.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// String returns the number of succeeded, skipped and failed repositories, followed by the reasons
+// why repositories were skipped or failed
+func (s Summary) String() string {
+	if len(s.Results) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString(i18n.T("summary.counts", s.count(StatusSucceeded), s.count(StatusSkipped), s.count(StatusFailed)))
+	for _, status := range []string{StatusSkipped, StatusFailed} {
+		if s.count(status) == 0 {
+			continue
+		}
+		b.WriteString("\n" + i18n.T("summary."+status))
+		for _, r := range s.Results {
+			if r.Status == status {
+				b.WriteString(fmt.Sprintf("\n  %s: %s", r.Repository, r.Error))
+			}
+		}
+	}
+	return b.String()
+}
+
+// ExitError returns the error that reports the outcome of the command with its exit code, or nil if
+// no repository failed. The error that stopped the command was already shown if reported is set, and
+// the failed repositories are always shown by the summary.
+func (s Summary) ExitError(reported bool) error {
+	if s.Err != nil {
+		code := ExitError
+		if isAuthError(s.Err) {
+			code = ExitAuthFailure
+		}
+		return &ExitCodeError{Code: code, Err: s.Err, Reported: reported}
+	}
+	failed := s.count(StatusFailed)
+	if failed == 0 {
+		return nil
+	}
+	for _, r := range s.Results {
+		if r.authFailure {
+			return &ExitCodeError{Code: ExitAuthFailure, Err: authError(r.Error), Reported: true}
+		}
+	}
+	code := ExitPartialFailure
+	if failed == len(s.Results) {
+		code = ExitTotalFailure
+	}
+	return &ExitCodeError{Code: code, Err: errors.New(i18n.T("summary.failures", failed, len(s.Results))), Reported: true}
+}
+
+// summarizer is a TUI model that processes the students' repositories
+type summarizer interface {
+	tea.Model
+	Summary() Summary
+}
+
+// RunTUI runs the model, prints the summary of its results and returns the error that reports them
+func RunTUI(m summarizer) error {
+	p := tea.NewProgram(m)
+	notice, logged := retryNotice, logNotice
+	retryNotice = func(message string) {
+		p.Println(message)
+	}
+	logNotice = retryNotice
+	defer func() {
+		retryNotice, logNotice = notice, logged
+	}()
+	final, err := p.Run()
+	if err != nil {
+		return &ExitCodeError{Code: ExitError, Err: fmt.Errorf("%s: %w", i18n.T("summary.tuiFailed"), err)}
+	}
+	summary := final.(summarizer).Summary()
+	if s := summary.String(); s != "" {
+		fmt.Println(s)
+	}
+	return summary.ExitError(true)
+}
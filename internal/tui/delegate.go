@@ -57,3 +57,67 @@ func (d ItemDelegate) Render(w legal.Writer, m list.Model, index int, listItem l
 		return
 	}
 }
+
+// SubmissionStatus represents the grading status of a student's submission
+type SubmissionStatus int
+
+const (
+	Ungraded SubmissionStatus = iota
+	Scored
+	Reviewed
+)
+
+// SubmissionItem represents a student's submission in the grading list
+type SubmissionItem struct {
+	Name string
+	// Student holds the real names of the students, when they are in the course roster
+	Student string
+	Grade   string
+	Status  SubmissionStatus
+}
+
+func (i SubmissionItem) FilterValue() string { return i.Name + " " + i.Student }
+
+// SubmissionDelegate renders submissions along with their grading status
+type SubmissionDelegate struct {
+	styles *ClaroStyles
+}
+
+// NewSubmissionDelegate creates a new SubmissionDelegate
+func NewSubmissionDelegate(styles *ClaroStyles) *SubmissionDelegate {
+	return &SubmissionDelegate{styles: styles}
+}
+
+func (d SubmissionDelegate) Height() int                             { return 1 }
+func (d SubmissionDelegate) Spacing() int                            { return 0 }
+func (d SubmissionDelegate) Update(_ tea.Msg, _ *list.Model) tea.Cmd { return nil }
+func (d SubmissionDelegate) Render(w legal.Writer, m list.Model, index int, listItem list.Item) {
+	i, ok := listItem.(SubmissionItem)
+	if !ok {
+		return
+	}
+	var mark string
+	switch i.Status {
+	case Reviewed:
+		mark = CheckMark.String()
+	case Scored:
+		mark = BowtieMark.String()
+	default:
+		mark = PendingMark.String()
+	}
+	str := fmt.Sprintf("%s %s", mark, i.Name)
+	if i.Student != "" {
+		str += DetailStyle.Render(" " + i.Student)
+	}
+	if i.Grade != "" {
+		str += GradeStyle.Render(" " + i.Grade)
+	}
+
+	fn := d.styles.Item.Render
+	if index == m.Index() {
+		fn = func(s ...string) string {
+			return d.styles.SelectedItem.Render("> " + strings.Join(s, " "))
+		}
+	}
+	_, _ = fmt.Fprint(w, fn(str))
+}
@@ -5,7 +5,10 @@ package tui
 Copyright © 2022-2024 Emerson Ribeiro de Mello <mello@ifsc.edu.br>
 */
 
-import "github.com/charmbracelet/bubbles/key"
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/emersonmello/claro/internal/i18n"
+)
 
 type KeyMap struct {
 	Left  key.Binding
@@ -23,11 +26,68 @@ func ClaroKeyMap() *KeyMap {
 	return &KeyMap{
 		Left: key.NewBinding(
 			key.WithKeys("left", "h"),
-			key.WithHelp("←/h", "to go back"),
+			key.WithHelp("←/h", i18n.T("key.back")),
 		),
 		Right: key.NewBinding(
 			key.WithKeys("right", "enter"),
-			key.WithHelp("→/enter", "confirm"),
+			key.WithHelp("→/enter", i18n.T("key.confirm")),
+		),
+	}
+}
+
+// GradingKeyMap represents the keybindings of the grading TUI
+type GradingKeyMap struct {
+	Edit         key.Binding
+	NextUngraded key.Binding
+	Score        key.Binding
+	Reviewed     key.Binding
+	ScrollDown   key.Binding
+	ScrollUp     key.Binding
+	Quit         key.Binding
+}
+
+// ShortHelp returns keybindings to be shown in the mini help view. It's part
+// of the key.Map interface.
+func (k GradingKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Edit, k.NextUngraded, k.Score, k.Reviewed, k.ScrollDown, k.ScrollUp, k.Quit}
+}
+
+// FullHelp returns keybindings for the expanded help view. It's part of the
+// key.Map interface.
+func (k GradingKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+// ClaroGradingKeyMap returns the keybindings for the grading TUI
+func ClaroGradingKeyMap() *GradingKeyMap {
+	return &GradingKeyMap{
+		Edit: key.NewBinding(
+			key.WithKeys("e", "enter"),
+			key.WithHelp("e/enter", i18n.T("key.edit")),
+		),
+		NextUngraded: key.NewBinding(
+			key.WithKeys("n"),
+			key.WithHelp("n", i18n.T("key.nextUngraded")),
+		),
+		Score: key.NewBinding(
+			key.WithKeys("s"),
+			key.WithHelp("s", i18n.T("key.score")),
+		),
+		Reviewed: key.NewBinding(
+			key.WithKeys("r"),
+			key.WithHelp("r", i18n.T("key.reviewed")),
+		),
+		ScrollDown: key.NewBinding(
+			key.WithKeys("J", "pgdown"),
+			key.WithHelp("J/pgdn", i18n.T("key.scrollDown")),
+		),
+		ScrollUp: key.NewBinding(
+			key.WithKeys("K", "pgup"),
+			key.WithHelp("K/pgup", i18n.T("key.scrollUp")),
+		),
+		Quit: key.NewBinding(
+			key.WithKeys("q", "ctrl+c"),
+			key.WithHelp("q", i18n.T("key.quit")),
 		),
 	}
 }
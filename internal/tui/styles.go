@@ -20,6 +20,11 @@ var (
 	CheckMark              = lipgloss.NewStyle().Foreground(lipgloss.Color("42")).SetString("✓")
 	BowtieMark             = lipgloss.NewStyle().Foreground(lipgloss.Color("#F8BA00")).SetString("⧖")
 	ErrorMark              = lipgloss.NewStyle().Foreground(lipgloss.Color("#FF2D27")).SetString("𐄂")
+	PendingMark            = lipgloss.NewStyle().Foreground(lipgloss.Color("241")).SetString("·")
+	GradeStyle             = lipgloss.NewStyle().Foreground(lipgloss.Color("#E9E64D")).Italic(true)
+	DetailStyle            = lipgloss.NewStyle().Foreground(lipgloss.Color("#E9E64D")).Italic(true)
+	ReasonStyle            = lipgloss.NewStyle().Foreground(lipgloss.Color("#783D38")).Italic(true)
+	PaneStyle              = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240")).Padding(0, 1)
 )
 
 // ClaroStyles represents the styles used in the Claro TUI
@@ -9,37 +9,34 @@ import (
 	"fmt"
 
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/emersonmello/claro/internal/i18n"
 	"github.com/github/gh-classroom/pkg/classroom"
 )
 
 type ClassroomList []classroom.Classroom
 type AssignmentsList []classroom.Assignment
 
-type SuccessfullMsg string
-type NewCommits string
 type ErrorMsg string
-type SuccessfullPullMsg string
-type ErrorPullMsg string
+type AuthErrorMsg string
 type AssignmentDirError string
+type StarterRepositoryMsg string
+type EditorFinishedMsg struct{ Err error }
 
 var GitHubCliInstalled bool
 var UserGitHubPAT string
 
 const (
 	DefaultWidth = 80
-	useMsg       = "\nThe directory should have been created using the 'clone' command and should include:" +
-		"\n- Subdirectories, each named after a student's repository (e.g., assignment-01-JohnDoeStudent)." +
-		"\n- Markdown files, each named with the pattern 'grade-<repository-name>.md' (e.g., grade-assignment-01-JohnDoeStudent.md)."
 )
 
 func UseErrorMsg(cmdName string) string {
-	return ErrorStyle.Render(fmt.Sprintf("The '%s' command requires a directory containing student repositories and their corresponding grade files.", cmdName)) +
-		useMsg +
-		fmt.Sprintf("\n\nEnsure that this structure is followed for the '%s' command to work correctly.\n", cmdName)
+	return ErrorStyle.Render(i18n.T("usage.error", cmdName)) +
+		i18n.T("usage.structure") +
+		i18n.T("usage.ensure", cmdName)
 }
 
 func LongHelpMsg(shortHelpMsg string) string {
-	return fmt.Sprintf("%s\n%s", shortHelpMsg, useMsg)
+	return fmt.Sprintf("%s\n%s", shortHelpMsg, i18n.T("usage.structure"))
 }
 
 // MakeClassroomList creates a list of classrooms
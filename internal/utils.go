@@ -2,9 +2,11 @@
 package internal
 
 import (
-	"io"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
 )
 
 /*
@@ -16,32 +18,36 @@ func executeCommand(cmd *exec.Cmd, directory string) ([]byte, error) {
 	return cmd.Output()
 }
 
-func copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
-		return err
+// expandHomeDirectory replaces a leading "~" in the directory with the user's home directory
+func expandHomeDirectory(directory string) string {
+	if strings.HasPrefix(directory, "~") {
+		dirname, _ := os.UserHomeDir()
+		directory = filepath.Join(dirname, directory[1:])
 	}
-	defer func(sourceFile *os.File) {
-		_ = sourceFile.Close()
-	}(sourceFile)
+	return directory
+}
 
-	destinationFile, err := os.Create(dst)
-	if err != nil {
-		return err
+// editorCommand returns the command that opens the file in the user's editor ($VISUAL or $EDITOR)
+func editorCommand(path string) *exec.Cmd {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
 	}
-	defer func(destinationFile *os.File) {
-		_ = destinationFile.Close()
-	}(destinationFile)
-
-	_, err = io.Copy(destinationFile, sourceFile)
-	if err != nil {
-		return err
+	if editor == "" {
+		editor = "vi"
+		if runtime.GOOS == "windows" {
+			editor = "notepad"
+		}
 	}
+	args := strings.Fields(editor)
+	return exec.Command(args[0], append(args[1:], path)...)
+}
 
-	err = destinationFile.Sync()
-	if err != nil {
-		return err
+// progressPercent returns the progress bar's percentage while processing the index-th of n
+// repositories, counting from 0. A single repository shows a full bar instead of dividing by zero.
+func progressPercent(index int, n int) float64 {
+	if n <= 1 {
+		return 1
 	}
-
-	return nil
+	return float64(index) / float64(n-1)
 }
@@ -0,0 +1,22 @@
+package internal
+
+import "testing"
+
+func TestProgressPercent(t *testing.T) {
+	tests := []struct {
+		index, n int
+		want     float64
+	}{
+		{0, 1, 1},
+		{0, 0, 1},
+		{0, 2, 0},
+		{1, 2, 1},
+		{1, 3, 0.5},
+		{3, 5, 0.75},
+	}
+	for _, tt := range tests {
+		if got := progressPercent(tt.index, tt.n); got != tt.want {
+			t.Errorf("progressPercent(%d, %d) = %v, want %v", tt.index, tt.n, got, tt.want)
+		}
+	}
+}
@@ -12,6 +12,8 @@ Available Commands:
 	clone       Clone all students assignments from a GitHub Classroom
 	completion  Generate the autocompletion script for the specified shell
 	config      Configure claro's properties (commit message, filename, etc)
+	diff        Compare each student's repository with the assignment's starter code
+	grade       Grade students' submissions interactively
 	help        Help about any command
 	pull        Incorporate changes from students' remote repositories into local copy
 	push        Add, commit, and push the grading file to each student's remote repository
@@ -20,9 +22,13 @@ Available Commands:
 package main
 
 import (
+	"os"
+
 	"github.com/emersonmello/claro/cmd"
 )
 
 func main() {
-	_ = cmd.Execute()
+	if err := cmd.Execute(); err != nil {
+		os.Exit(cmd.ExitCode(err))
+	}
 }